@@ -6,6 +6,7 @@ require (
 	github.com/gdamore/tcell v1.3.0
 	github.com/mitchellh/go-ps v0.0.0-20190716172923-621e5597135b
 	github.com/rivo/tview v0.0.0-20190324182152-8a9e26fab0ff
+	golang.org/x/sys v0.1.0
 )
 
 require (
@@ -13,6 +14,5 @@ require (
 	github.com/lucasb-eyer/go-colorful v1.0.2 // indirect
 	github.com/mattn/go-runewidth v0.0.4 // indirect
 	github.com/rivo/uniseg v0.1.0 // indirect
-	golang.org/x/sys v0.0.0-20190626150813-e07cf5db2756 // indirect
 	golang.org/x/text v0.3.0 // indirect
 )
@@ -0,0 +1,49 @@
+package gui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/dixler/pst/gui/proc"
+)
+
+var deletedHeaders = []string{
+	"Size",
+	"Pid",
+	"Cmd",
+	"Fd",
+	"Path",
+}
+
+// DeletedView lists the deleted files held open on the system, largest
+// first.
+type DeletedView struct {
+	*PidTable
+}
+
+func NewDeletedView() *DeletedView {
+	return &DeletedView{
+		PidTable: NewPidTable("deleted open files"),
+	}
+}
+
+func (p *DeletedView) UpdateView() {
+	files := proc.AllDeletedFiles()
+
+	rows := make([][]string, 0, len(files))
+	pids := make([]proc.PID, 0, len(files))
+	for _, f := range files {
+		rows = append(rows, []string{
+			formatBytes(f.Size),
+			f.Pid.String(),
+			strings.TrimSpace(proc.GetCommand(f.Pid)),
+			strconv.Itoa(f.Fd.Fd),
+			f.Path(),
+		})
+		pids = append(pids, f.Pid)
+	}
+	p.SetRows(deletedHeaders, rows, pids)
+	p.Select(1, 0)
+	p.SetTitle(fmt.Sprintf("deleted open files [%s reclaimable]", formatBytes(proc.DeletedSize(files))))
+}
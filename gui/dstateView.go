@@ -0,0 +1,70 @@
+package gui
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/dixler/pst/gui/proc"
+)
+
+var dstateHeaders = []string{
+	"Stuck",
+	"Pid",
+	"Tid",
+	"Comm",
+	"Wchan",
+}
+
+// DStateView lists the tasks in uninterruptible sleep on the system,
+// longest stuck first.
+type DStateView struct {
+	*PidTable
+	tasks []proc.StuckTask
+}
+
+func NewDStateView() *DStateView {
+	return &DStateView{
+		PidTable: NewPidTable("uninterruptible tasks"),
+	}
+}
+
+// SelectedTask returns the task of the selected row.
+func (p *DStateView) SelectedTask() (proc.StuckTask, bool) {
+	row, _ := p.GetSelection()
+	if row < 1 || row > len(p.tasks) {
+		return proc.StuckTask{}, false
+	}
+	return p.tasks[row-1], true
+}
+
+// UpdateView replaces the listed tasks, keeping the selected task selected.
+func (p *DStateView) UpdateView(tasks []proc.StuckTask) {
+	selected, hasSelection := p.SelectedTask()
+	now := time.Now()
+	rows := make([][]string, 0, len(tasks))
+	pids := make([]proc.PID, 0, len(tasks))
+	for _, t := range tasks {
+		stuck := now.Sub(t.Since).Round(time.Second).String()
+		if t.Before {
+			// stuck before pst started sampling
+			stuck = ">" + stuck
+		}
+		wchan := t.Wchan
+		if wchan == "" {
+			wchan = "-"
+		}
+		rows = append(rows, []string{stuck, t.Pid.String(), t.Tid.String(), t.Comm, wchan})
+		pids = append(pids, t.Pid)
+	}
+	p.tasks = tasks
+	p.SetRows(dstateHeaders, rows, pids)
+	if hasSelection {
+		for i, t := range tasks {
+			if t.Tid == selected.Tid {
+				p.Select(i+1, 0)
+				break
+			}
+		}
+	}
+	p.SetTitle(fmt.Sprintf("uninterruptible tasks [%d]", len(tasks)))
+}
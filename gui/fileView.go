@@ -1,6 +1,12 @@
 package gui
 
 import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+
 	"github.com/dixler/pst/gui/proc"
 	"github.com/rivo/tview"
 )
@@ -20,12 +26,16 @@ func NewProcessFileView() *ProcessFileView {
 }
 
 func (p *ProcessFileView) UpdateViewWithPid(g *Gui, pid proc.PID) {
-	text := ""
+	text := renderFdTrend(g.ProcessManager.GetFdTrend(pid))
 	info, err := proc.OpenFiles(pid)
 	if err != nil {
-		text = err.Error()
+		text += err.Error()
 	} else {
-		text = info
+		text += info
+	}
+
+	if sockets, err := proc.ProcessSockets(pid); err == nil && len(sockets) > 0 {
+		text = strings.TrimRight(text, "\n") + "\n\n" + renderSockets(sockets)
 	}
 
 	g.App.QueueUpdateDraw(func() {
@@ -33,3 +43,56 @@ func (p *ProcessFileView) UpdateViewWithPid(g *Gui, pid proc.PID) {
 		p.ScrollToBeginning()
 	})
 }
+
+func renderFdTrend(trend proc.FdTrend) string {
+	if len(trend.Samples) == 0 {
+		return ""
+	}
+	first, last := trend.Samples[0], trend.Samples[len(trend.Samples)-1]
+
+	kinds := make([]string, 0, len(last.Kinds))
+	for _, k := range []string{"file", "socket", "pipe", "anon", "other"} {
+		if n := last.Kinds[k]; n > 0 {
+			kinds = append(kinds, fmt.Sprintf("%s %d", k, n))
+		}
+	}
+
+	limit := "unlimited"
+	if trend.Limit == 0 {
+		limit = "?"
+	} else if trend.Limit != proc.Unlimited {
+		limit = fmt.Sprintf("%d (%d%%)", trend.Limit, uint64(last.Total())*100/trend.Limit)
+	}
+
+	text := fmt.Sprintf("[yellow]fds:[white] %d / %s [%s], %+d over %s\n", last.Total(), limit,
+		strings.Join(kinds, ", "), last.Total()-first.Total(),
+		last.Time.Sub(first.Time).Round(time.Second))
+
+	if trend.Leaking() {
+		growth := trend.Growth()
+		growing := make([]string, 0, len(growth))
+		for _, k := range trend.GrowingKinds() {
+			growing = append(growing, fmt.Sprintf("%s %+d", k, growth[k]))
+		}
+		text += fmt.Sprintf("[red]suspected fd leak: %s[white]\n", strings.Join(growing, ", "))
+	}
+	return text + "\n"
+}
+
+func renderSockets(sockets []proc.FdSocket) string {
+	buf := bytes.Buffer{}
+	w := tabwriter.NewWriter(&buf, 0, 8, 2, ' ', 0)
+	fmt.Fprintf(w, "FD\tPROTO\tSTATE\tLOCAL\tREMOTE\tPEER\n")
+	for _, s := range sockets {
+		peers := make([]string, 0, len(s.Peers))
+		for _, peer := range s.Peers {
+			peers = append(peers, fmt.Sprintf("%s(%s):%d", peer.Pid,
+				strings.TrimSpace(proc.GetCommand(peer.Pid)), peer.Fd))
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\n", s.Fd.Fd, s.Proto, s.State,
+			s.Local, s.Remote, strings.Join(peers, ", "))
+	}
+	w.Flush()
+
+	return highlightHeader(buf.String())
+}
@@ -0,0 +1,35 @@
+package gui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// highlightHeader colors the first line of a tabwriter table.
+func highlightHeader(table string) string {
+	result := strings.SplitN(table, "\n", 2)
+	result[0] = fmt.Sprintf("[yellow]%s[white]", result[0])
+	return strings.Join(result, "\n")
+}
+
+func formatDuration(d time.Duration) string {
+	if d < time.Microsecond {
+		return d.String()
+	}
+	return d.Round(time.Microsecond).String()
+}
+
+// formatBytes renders n with a binary unit, e.g. "1.5 GiB".
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,101 @@
+package gui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dixler/pst/gui/proc"
+)
+
+// Grouping splits the process table into processes sharing a key.
+type Grouping struct {
+	Name string
+	// Key returns the group of pid, "" when it cannot be determined.
+	Key func(pid proc.PID) string
+	// Summary describes a group in its row, pids being its members.
+	Summary func(key string, pids []proc.PID) string
+}
+
+var groupings = []Grouping{
+	{Name: "cgroup", Key: cgroupKey, Summary: cgroupSummary},
+	{Name: "unit", Key: proc.GetUnit, Summary: cgroupSummary},
+	{Name: "pid ns", Key: namespaceKey("pid")},
+	{Name: "net ns", Key: namespaceKey("net")},
+	{Name: "mnt ns", Key: namespaceKey("mnt")},
+}
+
+func namespaceKey(ns string) func(proc.PID) string {
+	return func(pid proc.PID) string {
+		inode, err := proc.GetNamespace(pid, ns)
+		if err != nil {
+			return ""
+		}
+		return fmt.Sprintf("%s:[%d]", ns, inode)
+	}
+}
+
+func cgroupKey(pid proc.PID) string {
+	path, _ := proc.GetCgroupPath(pid)
+	return path
+}
+
+func cgroupSummary(key string, pids []proc.PID) string {
+	if len(pids) == 0 {
+		return ""
+	}
+	cgroups, err := proc.GetCgroups(pids[0])
+	if err != nil {
+		return ""
+	}
+	return formatCgroupStats(proc.GetCgroupStats(cgroups))
+}
+
+func formatCgroupStats(stats proc.CgroupStats) string {
+	var parts []string
+	if stats.CPU >= 0 {
+		parts = append(parts, "cpu "+stats.CPU.Round(time.Millisecond).String())
+	}
+	if stats.Memory >= 0 {
+		parts = append(parts, "mem "+formatBytes(stats.Memory))
+	}
+	if stats.Pids >= 0 {
+		parts = append(parts, fmt.Sprintf("pids %d", stats.Pids))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// groupRows orders pids by group, keeping their order within a group. A
+// group starts with an empty pid whose label is in the returned map.
+func (g Grouping) groupRows(pids []proc.PID) ([]proc.PID, map[int]string) {
+	members := make(map[string][]proc.PID)
+	for _, pid := range pids {
+		key := g.Key(pid)
+		members[key] = append(members[key], pid)
+	}
+	keys := make([]string, 0, len(members))
+	for key := range members {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	rows := make([]proc.PID, 0, len(pids)+len(keys))
+	labels := make(map[int]string, len(keys))
+	for _, key := range keys {
+		name := key
+		if name == "" {
+			name = "(unknown)"
+		}
+		label := fmt.Sprintf("%s (%d)", name, len(members[key]))
+		if g.Summary != nil {
+			if summary := g.Summary(key, members[key]); summary != "" {
+				label += " " + summary
+			}
+		}
+		labels[len(rows)] = label
+		rows = append(rows, "")
+		rows = append(rows, members[key]...)
+	}
+	return rows, labels
+}
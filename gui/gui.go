@@ -1,10 +1,15 @@
 package gui
 
 import (
+	"fmt"
 	"log"
+	"path"
+	"sort"
+	"sync/atomic"
 	"time"
 
 	"github.com/dixler/pst/gui/proc"
+	"github.com/gdamore/tcell"
 	"github.com/rivo/tview"
 )
 
@@ -15,8 +20,29 @@ const (
 	ProcessEnvPanel
 	ProcessTreePanel
 	ProcessFilePanel
+	ProbePanel
+	SyscallPanel
+	SyscallStatPanel
+	NetPanel
+	ListenPanel
+	IPCPanel
+	HolderPanel
+	DeletedPanel
+	MemoryPanel
+	ThreadPanel
+	StackPanel
+	SecurityPanel
+	LimitsPanel
+	DStatePanel
+	ProfilePanel
 )
 
+// PidView is a panel showing something about the selected process.
+type PidView interface {
+	tview.Primitive
+	UpdateViewWithPid(g *Gui, pid proc.PID)
+}
+
 type Gui struct {
 	FilterInput     *tview.InputField
 	ProcessManager  *ProcessManager
@@ -24,10 +50,29 @@ type Gui struct {
 	ProcessTreeView *ProcessTreeView
 	ProcessEnvView  *EnvView
 	ProcessFileView *ProcessFileView
+	ListenView      *ListenView
+	HolderView      *HolderView
+	DeletedView     *DeletedView
+	StackView       *StackView
+	DStateView      *DStateView
 	NaviView        *NaviView
-	App             *tview.Application
-	Pages           *tview.Pages
-	updateChannel   chan proc.PID
+	DetailPages     *tview.Pages
+	DetailViews     []PidView
+	detailCurrent   int32
+	// followSubtree makes the tracers follow the subtree of the selection
+	// rather than the selected process alone.
+	followSubtree int32
+	App           *tview.Application
+	Pages         *tview.Pages
+	updateChannel chan proc.PID
+	traceChannel  chan traceTarget
+	traceRequests chan proc.Filter
+	// traced is what the tracers are pinned to, empty while they follow
+	// the selection.
+	traced traceTarget
+	// systemKind is the kind of the open system view, 0 if none. It is
+	// read by the redraw loop, so accessed atomically.
+	systemKind int32
 	Panels
 }
 
@@ -46,6 +91,7 @@ func New() *Gui {
 	processFileView := NewProcessFileView()
 	naviView := NewNaviView()
 	updateChannel := make(chan proc.PID, 50)
+	traceChannel := make(chan traceTarget, 10)
 
 	g := &Gui{
 		FilterInput:     filterInput,
@@ -55,8 +101,16 @@ func New() *Gui {
 		ProcessTreeView: processTreeView,
 		ProcessEnvView:  processEnvView,
 		ProcessFileView: processFileView,
+		ListenView:      NewListenView(),
+		HolderView:      NewHolderView(),
+		DeletedView:     NewDeletedView(),
+		StackView:       NewStackView(),
+		DStateView:      NewDStateView(),
 		NaviView:        naviView,
+		DetailPages:     tview.NewPages(),
 		updateChannel:   updateChannel,
+		traceChannel:    traceChannel,
+		traceRequests:   make(chan proc.Filter, 1),
 	}
 
 	redraw := func(pid proc.PID) {
@@ -64,6 +118,9 @@ func New() *Gui {
 		g.ProcessTreeView.UpdateTree(pid)
 		g.ProcessEnvView.UpdateViewWithPid(g, pid)
 		g.ProcessFileView.UpdateViewWithPid(g, pid)
+		if i := int(atomic.LoadInt32(&g.detailCurrent)); i < len(g.DetailViews) {
+			g.DetailViews[i].UpdateViewWithPid(g, pid)
+		}
 		g.NaviView.UpdateView(g)
 	}
 
@@ -71,20 +128,88 @@ func New() *Gui {
 		duration := 250 * time.Millisecond
 		t := time.NewTicker(duration)
 		var curPid *proc.PID = nil
+		// tracers follow the selected process, or its subtree, unless
+		// pinned, waiting a little so scrolling through the list doesn't
+		// restart bpftrace on every row. Subtrees are re-resolved every
+		// second so children started later are picked up.
+		var selectedAt time.Time
+		var pinned traceTarget
+		var traced string
+		var resolvedAt time.Time
 		for {
 			select {
 			case <-t.C:
+				// keep the Stuck column counting while the list is open
+				if int(atomic.LoadInt32(&g.systemKind)) == DStatePanel {
+					tasks := g.ProcessManager.GetStuckTasks()
+					g.App.QueueUpdateDraw(func() {
+						if int(atomic.LoadInt32(&g.systemKind)) == DStatePanel {
+							g.DStateView.UpdateView(tasks)
+						}
+					})
+				}
+
+				root, subtree := pinned.Root, pinned.Root != ""
+				if pinned.Label == "" && curPid != nil && time.Since(selectedAt) > time.Second {
+					root, subtree = *curPid, atomic.LoadInt32(&g.followSubtree) != 0
+				}
+				switch {
+				case pinned.Label != "" && root == "":
+					if traced != pinned.Label {
+						traced = pinned.Label
+						g.trace(pinned.Filter)
+					}
+				case root != "" && !subtree:
+					if key := root.String(); key != traced {
+						traced = key
+						g.trace(proc.Filter{Pids: []proc.PID{root}})
+					}
+				case root != "" && time.Since(resolvedAt) > time.Second:
+					resolvedAt = time.Now()
+					pids := proc.GetDescendants(root)
+					sort.Slice(pids, func(i, j int) bool { return pids[i] < pids[j] })
+					if key := fmt.Sprint(pids); key != traced {
+						traced = key
+						g.trace(proc.Filter{Pids: pids})
+					}
+				}
 				if curPid == nil {
 					continue
 				}
 				redraw(*curPid)
 			case pid := <-g.updateChannel:
 				curPid = &pid
+				selectedAt = time.Now()
+				resolvedAt = time.Time{}
 				t.Reset(duration)
+			case target := <-g.traceChannel:
+				pinned = target
+				traced = ""
+				resolvedAt = time.Time{}
 			}
 		}
 	}()
 
+	go g.restartTracers()
+
+	// keep the cpu column current, reading /proc off the UI goroutine
+	go func() {
+		for range time.Tick(time.Second) {
+			views := make(chan rowView, 1)
+			g.App.QueueUpdate(func() {
+				views <- g.ProcessManager.rowView()
+			})
+			rows := g.ProcessManager.collectRows(<-views)
+			g.App.QueueUpdateDraw(func() {
+				// the filter, sort or grouping changed meanwhile
+				if rows.view != g.ProcessManager.rowView() {
+					return
+				}
+				g.showProcessRows(rows)
+			})
+		}
+	}()
+
 	g.Panels = Panels{
 		Panels: []tview.Primitive{
 			filterInput,
@@ -104,9 +229,57 @@ func New() *Gui {
 		},
 	}
 
+	g.AddDetailView(SyscallPanel, NewSyscallView())
+	g.AddDetailView(SyscallStatPanel, NewSyscallStatView())
+	g.AddDetailView(NetPanel, NewNetView())
+	g.AddDetailView(IPCPanel, NewIPCView())
+	g.AddDetailView(MemoryPanel, NewMemoryView())
+	g.AddDetailView(ThreadPanel, NewThreadView())
+	g.AddDetailView(SecurityPanel, NewSecurityView())
+	g.AddDetailView(LimitsPanel, NewLimitsView())
+	g.AddDetailView(ProfilePanel, NewProfileView())
+	g.addProbeViews()
+
 	return g
 }
 
+// AddDetailView adds a panel to the detail area below the process info.
+// Only the detail view last focused is shown and kept up to date.
+func (g *Gui) AddDetailView(kind int, v PidView) {
+	g.DetailPages.AddPage(fmt.Sprintf("detail-%d", len(g.DetailViews)), v, true, len(g.DetailViews) == 0)
+	g.DetailViews = append(g.DetailViews, v)
+	g.Panels.Panels = append(g.Panels.Panels, v)
+	g.Panels.Kinds = append(g.Panels.Kinds, kind)
+}
+
+func (g *Gui) showDetailView(p tview.Primitive) {
+	for i, v := range g.DetailViews {
+		if v == p {
+			g.DetailPages.SwitchToPage(fmt.Sprintf("detail-%d", i))
+			atomic.StoreInt32(&g.detailCurrent, int32(i))
+			return
+		}
+	}
+}
+
+func (g *Gui) addProbeViews() {
+	files, err := proc.ProbeFiles(proc.ProbeDir())
+	if err != nil {
+		g.AddDetailView(ProbePanel, NewProbeView("probes", nil, err))
+		return
+	}
+
+	for _, f := range files {
+		probe, err := proc.LoadProbe(f)
+		if err != nil {
+			g.AddDetailView(ProbePanel, NewProbeView(path.Base(f), nil, err))
+			continue
+		}
+		source, err := g.ProcessManager.AddProbe(probe)
+		g.AddDetailView(ProbePanel, NewProbeView(probe.Title, source, err))
+	}
+}
+
 func (g *Gui) Confirm(message, doneLabel string, primitive tview.Primitive, doneFunc func()) {
 	modal := tview.NewModal().
 		SetText(message).
@@ -121,10 +294,84 @@ func (g *Gui) Confirm(message, doneLabel string, primitive tview.Primitive, done
 		})
 
 	g.Pages.AddAndSwitchToPage("modal", g.Modal(modal, 50, 29), true).ShowPage("main")
+	g.showSystemPage()
+}
+
+func (g *Gui) Input(label, text string, primitive tview.Primitive, doneFunc func(text string)) {
+	input := tview.NewInputField().SetLabel(label).SetText(text)
+	input.SetBorder(true)
+	input.SetDoneFunc(func(key tcell.Key) {
+		switch key {
+		case tcell.KeyEnter:
+			g.CloseAndSwitchPanel("modal", primitive)
+			doneFunc(input.GetText())
+		case tcell.KeyEscape:
+			g.CloseAndSwitchPanel("modal", primitive)
+		}
+	})
+
+	g.Pages.AddAndSwitchToPage("modal", g.Modal(input, 60, 3), true).ShowPage("main")
+	g.showSystemPage()
+}
+
+func (g *Gui) Message(message string, primitive tview.Primitive) {
+	modal := tview.NewModal().
+		SetText(message).
+		AddButtons([]string{"OK"}).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			g.CloseAndSwitchPanel("modal", primitive)
+		})
+
+	g.Pages.AddAndSwitchToPage("modal", g.Modal(modal, 50, 29), true).ShowPage("main")
+	g.showSystemPage()
+}
+
+// showSystemPage keeps an open system view visible under a modal.
+func (g *Gui) showSystemPage() {
+	if g.Pages.HasPage("system") {
+		g.Pages.ShowPage("system")
+	}
+}
+
+// ShowSystemView opens a full screen view over the main page, closed
+// with CloseSystemView.
+func (g *Gui) ShowSystemView(kind int, p tview.Primitive) {
+	atomic.StoreInt32(&g.systemKind, int32(kind))
+	g.Pages.AddAndSwitchToPage("system", p, true)
+	g.App.SetFocus(p)
+	g.NaviView.UpdateView(g)
+}
+
+func (g *Gui) CloseSystemView() {
+	atomic.StoreInt32(&g.systemKind, 0)
+	g.Pages.RemovePage("system").ShowPage("main")
+	g.SwitchPanel(g.Panels.Panels[g.Panels.Current])
+	g.NaviView.UpdateView(g)
+}
+
+// JumpToProcess closes any system view and selects pid in the process
+// table, clearing the filter if it hides pid.
+func (g *Gui) JumpToProcess(pid proc.PID) {
+	if g.Pages.HasPage("system") {
+		g.CloseSystemView()
+	}
+	if !g.ProcessManager.SelectPid(pid) {
+		g.FilterInput.SetText("")
+		g.ProcessManager.SelectPid(pid)
+	}
+	for i, panel := range g.Panels.Panels {
+		if panel == g.ProcessManager {
+			g.Panels.Current = i
+		}
+	}
+	g.SwitchPanel(g.ProcessManager)
+	g.UpdateViews(pid)
+	g.NaviView.UpdateView(g)
 }
 
 func (g *Gui) CloseAndSwitchPanel(removePrimitive string, primitive tview.Primitive) {
 	g.Pages.RemovePage(removePrimitive).ShowPage("main")
+	g.showSystemPage()
 	g.SwitchPanel(primitive)
 }
 
@@ -137,15 +384,114 @@ func (g *Gui) Modal(p tview.Primitive, width, height int) tview.Primitive {
 
 func (g *Gui) SwitchPanel(p tview.Primitive) *tview.Application {
 	//g.UpdateViews()
+	g.showDetailView(p)
 	return g.App.SetFocus(p)
 }
 
+// RefreshProcesses rebuilds the process table and updates the views when
+// the process under the cursor changed, e.g. because the selected one exited.
+func (g *Gui) RefreshProcesses() {
+	pm := g.ProcessManager
+	g.showProcessRows(pm.collectRows(pm.rowView()))
+}
+
+func (g *Gui) showProcessRows(rows *processRows) {
+	var before proc.PID
+	if p := g.ProcessManager.Selected(); p != nil {
+		before = p.Pid
+	}
+	g.ProcessManager.setRows(rows)
+	if p := g.ProcessManager.Selected(); p != nil && p.Pid != before {
+		g.UpdateViews(p.Pid)
+	}
+}
+
 func (g *Gui) UpdateViews(pid proc.PID) {
 	g.updateChannel <- pid
 
 }
 
+// traceTarget is what the tracers can be pinned to instead of following
+// the selection: the subtree of Root, or else the processes Filter matches.
+type traceTarget struct {
+	Root   proc.PID
+	Filter proc.Filter
+	// Label names the target in the process list title.
+	Label string
+}
+
+// pinTracers pins the tracers to target, or returns them to following the
+// selection if already pinned to it.
+func (g *Gui) pinTracers(target traceTarget) {
+	if g.traced.Label == target.Label {
+		target = traceTarget{}
+	}
+	g.traced = target
+	g.traceChannel <- target
+	g.ProcessManager.SetTracing(target.Label)
+}
+
+// ToggleTraceSubtree pins the tracers to pid and its descendants.
+func (g *Gui) ToggleTraceSubtree(pid proc.PID) {
+	g.pinTracers(traceTarget{Root: pid, Label: pid.String()})
+}
+
+// ToggleTraceCgroup pins the tracers to the cgroup of pid.
+func (g *Gui) ToggleTraceCgroup(pid proc.PID) {
+	id, err := proc.GetCgroupID(pid)
+	if err != nil {
+		g.Message(err.Error(), g.ProcessManager)
+		return
+	}
+	label := fmt.Sprintf("cgroup %d", id)
+	if cg, err := proc.GetCgroupPath(pid); err == nil {
+		label = "cgroup " + cg
+	}
+	g.pinTracers(traceTarget{Filter: proc.Filter{Cgroup: id}, Label: label})
+}
+
+// TraceComm pins the tracers to the processes named comm, or returns them
+// to following the selection if comm is empty.
+func (g *Gui) TraceComm(comm string) {
+	if comm == "" {
+		if g.traced.Label != "" {
+			g.pinTracers(g.traced)
+		}
+		return
+	}
+	g.pinTracers(traceTarget{Filter: proc.Filter{Comm: comm}, Label: "comm " + comm})
+}
+
+// trace asks for the tracers to be restarted on filter. Restarts wait for
+// bpftrace to attach, so they run on their own goroutine and only the
+// latest request is kept while one is in progress.
+func (g *Gui) trace(filter proc.Filter) {
+	select {
+	case <-g.traceRequests:
+	default:
+	}
+	g.traceRequests <- filter
+}
+
+// restartTracers serves trace requests, reporting a failure to start them.
+func (g *Gui) restartTracers() {
+	for filter := range g.traceRequests {
+		if g.ProcessManager.TraceError() != nil {
+			// shown in the title, there is nothing to restart
+			continue
+		}
+		if err := g.ProcessManager.Trace(filter); err != nil {
+			g.App.QueueUpdateDraw(func() {
+				g.Message(err.Error(), g.ProcessManager)
+			})
+		}
+	}
+}
+
 func (g *Gui) CurrentPanelKind() int {
+	if kind := atomic.LoadInt32(&g.systemKind); kind != 0 {
+		return int(kind)
+	}
 	return g.Panels.Kinds[g.Panels.Current]
 }
 
@@ -167,7 +513,8 @@ func (g *Gui) Run() error {
 		AddItem(tview.NewGrid().
 			AddItem(g.ProcessTreeView, 0, 0, 1, 1, 0, 0, true).
 			AddItem(g.ProcessEnvView, 0, 1, 1, 1, 0, 0, true),
-			2, 1, 1, 1, 0, 0, true)
+			2, 1, 1, 1, 0, 0, true).
+		AddItem(g.DetailPages, 3, 1, 1, 1, 0, 0, true)
 
 	grid := tview.NewGrid().SetRows(1, 0, 2).
 		SetColumns(30).
@@ -0,0 +1,58 @@
+package gui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/dixler/pst/gui/proc"
+)
+
+var holderHeaders = []string{
+	"Pid",
+	"Cmd",
+	"Use",
+	"Fd",
+	"Target",
+}
+
+// HolderView lists the processes holding a file or mount point open.
+type HolderView struct {
+	*PidTable
+	target string
+}
+
+func NewHolderView() *HolderView {
+	return &HolderView{
+		PidTable: NewPidTable("file holders"),
+	}
+}
+
+func (p *HolderView) Target() string {
+	return p.target
+}
+
+// Lookup scans every process for target and shows the matches.
+func (p *HolderView) Lookup(target string) error {
+	holders, err := proc.FileHolders(target)
+	if err != nil {
+		return err
+	}
+	p.target = target
+
+	rows := make([][]string, 0, len(holders))
+	pids := make([]proc.PID, 0, len(holders))
+	for _, h := range holders {
+		fd := "-"
+		if h.Use == "fd" {
+			fd = strconv.Itoa(h.Fd.Fd)
+		}
+		rows = append(rows, []string{h.Pid.String(), strings.TrimSpace(proc.GetCommand(h.Pid)), h.Use, fd, h.Target})
+		pids = append(pids, h.Pid)
+	}
+	p.SetRows(holderHeaders, rows, pids)
+	p.Select(1, 0)
+	p.SetTitle(fmt.Sprintf("file holders [%s, %d found]", target, len(holders)))
+
+	return nil
+}
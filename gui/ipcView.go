@@ -0,0 +1,62 @@
+package gui
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/dixler/pst/gui/proc"
+)
+
+var ipcHeaders = []string{
+	"Fd",
+	"Kind",
+	"Mode",
+	"Peer Pid",
+	"Peer Cmd",
+	"Peer Fd",
+	"Peer Mode",
+}
+
+// IPCView lists the processes on the other end of the pipes and unix
+// sockets of a process.
+type IPCView struct {
+	*PidTable
+}
+
+func NewIPCView() *IPCView {
+	return &IPCView{
+		PidTable: NewPidTable("process ipc"),
+	}
+}
+
+func (p *IPCView) UpdateViewWithPid(g *Gui, pid proc.PID) {
+	links, err := proc.IPCLinks(pid)
+
+	rows := make([][]string, 0, len(links))
+	pids := make([]proc.PID, 0, len(links))
+	if err != nil {
+		rows = append(rows, []string{err.Error()})
+		pids = append(pids, "")
+	}
+	for _, l := range links {
+		peers := l.Peers
+		if len(peers) == 0 {
+			peers = []proc.IPCEnd{{}}
+		}
+		for _, peer := range peers {
+			row := []string{strconv.Itoa(l.Fd.Fd), l.Kind, l.Mode, "-", "", "", ""}
+			if peer.Pid != "" {
+				row[3] = peer.Pid.String()
+				row[4] = strings.TrimSpace(proc.GetCommand(peer.Pid))
+				row[5] = strconv.Itoa(peer.Fd.Fd)
+				row[6] = peer.Mode
+			}
+			rows = append(rows, row)
+			pids = append(pids, peer.Pid)
+		}
+	}
+
+	g.App.QueueUpdateDraw(func() {
+		p.SetRows(ipcHeaders, rows, pids)
+	})
+}
@@ -1,6 +1,10 @@
 package gui
 
 import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/dixler/pst/gui/proc"
@@ -52,6 +56,75 @@ func (g *Gui) ProcessManagerKeybinds() {
 					//g.ProcessManager.UpdateView()
 				})
 			}
+		case 'T':
+			if p := g.ProcessManager.Selected(); p != nil {
+				g.ToggleTraceSubtree(p.Pid)
+			}
+		case 'P':
+			if p := g.ProcessManager.Selected(); p != nil {
+				g.ToggleTraceCgroup(p.Pid)
+			}
+		case 'M':
+			comm := ""
+			if p := g.ProcessManager.Selected(); p != nil {
+				if st, err := proc.GetStat(p.Pid); err == nil {
+					comm = st.Comm
+				}
+			}
+			g.Input("trace comm (empty to follow selection):", comm, g.ProcessManager, func(text string) {
+				g.TraceComm(strings.TrimSpace(text))
+			})
+			return nil
+		case 'L':
+			if err := g.ListenView.UpdateView(); err != nil {
+				g.Message(err.Error(), g.ProcessManager)
+				return event
+			}
+			g.ShowSystemView(ListenPanel, g.ListenView)
+			return nil
+		case 'F':
+			g.Input("file or mount:", g.HolderView.Target(), g.ProcessManager, func(text string) {
+				if err := g.HolderView.Lookup(text); err != nil {
+					g.Message(err.Error(), g.ProcessManager)
+					return
+				}
+				g.ShowSystemView(HolderPanel, g.HolderView)
+			})
+			return nil
+		case 'D':
+			g.DeletedView.UpdateView()
+			g.ShowSystemView(DeletedPanel, g.DeletedView)
+			return nil
+		case 'C':
+			g.ProcessManager.ToggleSortByCPU()
+			return nil
+		case 'B':
+			g.ProcessManager.CycleGrouping()
+			return nil
+		case 'W':
+			if p := g.ProcessManager.Selected(); p != nil {
+				g.StackView.ShowProcess(g, p.Pid)
+				g.StackView.from = 0
+				g.ShowSystemView(StackPanel, g.StackView)
+			}
+			return nil
+		case 'S':
+			g.DStateView.UpdateView(g.ProcessManager.GetStuckTasks())
+			g.DStateView.Select(1, 0)
+			g.ShowSystemView(DStatePanel, g.DStateView)
+			return nil
+		case 'N':
+			if p := g.ProcessManager.Selected(); p != nil {
+				g.Input("same namespace (net, mnt, pid):", "net", g.ProcessManager, func(text string) {
+					ns := strings.TrimSpace(text)
+					if ns != "net" && ns != "mnt" && ns != "pid" {
+						g.Message(fmt.Sprintf("unknown namespace '%s'", ns), g.ProcessManager)
+						return
+					}
+					g.FilterInput.SetText(fmt.Sprintf("%sns:%s", ns, p.Pid))
+				})
+			}
+			return nil
 		}
 
 		g.GlobalKeybind(event)
@@ -64,6 +137,9 @@ func (g *Gui) ProcessManagerKeybinds() {
 		}
 
 		p := g.ProcessManager.Selected()
+		if p == nil {
+			return
+		}
 
 		g.UpdateViews(p.Pid)
 	})
@@ -84,7 +160,7 @@ func (g *Gui) FilterInputKeybinds() {
 
 	g.FilterInput.SetChangedFunc(func(text string) {
 		g.ProcessManager.FilterWord = text
-		g.ProcessManager.UpdateView()
+		g.RefreshProcesses()
 	})
 }
 
@@ -102,10 +178,20 @@ func (g *Gui) ProcessTreeViewKeybinds() {
 					proc.Kill(ref.(proc.PID))
 					// wait a little to finish process killing
 					time.Sleep(1 * time.Millisecond)
-					p := g.ProcessManager.Selected()
-					g.ProcessTreeView.UpdateTree(p.Pid)
+					if p := g.ProcessManager.Selected(); p != nil {
+						g.ProcessTreeView.UpdateTree(p.Pid)
+					}
 				})
 			}
+		case 'T':
+			if ref := node.GetReference(); ref != nil {
+				g.ToggleTraceSubtree(ref.(proc.PID))
+			}
+		case 'U':
+			g.ProcessTreeView.ToggleUnitRoot()
+			if p := g.ProcessManager.Selected(); p != nil {
+				g.ProcessTreeView.UpdateTree(p.Pid)
+			}
 		case 'l':
 			g.ProcessTreeView.ExpandToggle(node, true)
 		case 'h':
@@ -151,6 +237,320 @@ func (g *Gui) ProcessFileViewKeybinds() {
 	})
 }
 
+func (g *Gui) SyscallViewKeybinds() {
+	for _, v := range g.DetailViews {
+		p, ok := v.(*SyscallView)
+		if !ok {
+			continue
+		}
+		p.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+			switch event.Rune() {
+			case 's':
+				if selected := g.ProcessManager.Selected(); selected != nil {
+					if err := p.Toggle(selected.Pid); err != nil {
+						g.Message(err.Error(), p)
+					}
+				}
+			case 'c':
+				if err := p.ToggleChildren(); err != nil {
+					g.Message(err.Error(), p)
+				}
+			case 'p':
+				p.TogglePause()
+			case '/':
+				g.Input("syscall:", p.Filter(), p, func(text string) {
+					p.SetFilter(text)
+				})
+			case 'w':
+				file := "pst-syscalls.txt"
+				if selected := g.ProcessManager.Selected(); selected != nil {
+					file = fmt.Sprintf("pst-syscalls-%s.txt", selected.Pid)
+				}
+				g.Input("save to:", file, p, func(text string) {
+					n, err := p.Save(text)
+					if err != nil {
+						g.Message(err.Error(), p)
+						return
+					}
+					g.Message(fmt.Sprintf("saved %d syscalls to %s", n, text), p)
+				})
+			}
+			g.GlobalKeybind(event)
+			return event
+		})
+	}
+}
+
+func (g *Gui) SyscallStatViewKeybinds() {
+	for _, v := range g.DetailViews {
+		p, ok := v.(*SyscallStatView)
+		if !ok {
+			continue
+		}
+		p.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+			switch event.Rune() {
+			case 'o':
+				p.ToggleSort()
+			case 'c':
+				p.ToggleChildren()
+				// the tracers then follow the subtree of the selection
+				var subtree int32
+				if p.Children() {
+					subtree = 1
+				}
+				atomic.StoreInt32(&g.followSubtree, subtree)
+			}
+			g.GlobalKeybind(event)
+			return event
+		})
+	}
+}
+
+func (g *Gui) NetViewKeybinds() {
+	for _, v := range g.DetailViews {
+		if p, ok := v.(*NetView); ok {
+			p.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+				g.GlobalKeybind(event)
+				return event
+			})
+		}
+	}
+}
+
+func (g *Gui) IPCViewKeybinds() {
+	for _, v := range g.DetailViews {
+		p, ok := v.(*IPCView)
+		if !ok {
+			continue
+		}
+		p.SetSelectedFunc(func(row, col int) {
+			if pid := p.Selected(); pid != "" {
+				g.JumpToProcess(pid)
+			}
+		}).SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+			g.GlobalKeybind(event)
+			return event
+		})
+	}
+}
+
+func (g *Gui) MemoryViewKeybinds() {
+	for _, v := range g.DetailViews {
+		p, ok := v.(*MemoryView)
+		if !ok {
+			continue
+		}
+		p.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+			switch event.Rune() {
+			case 'o':
+				p.NextSort()
+			}
+			g.GlobalKeybind(event)
+			return event
+		})
+	}
+}
+
+func (g *Gui) ThreadViewKeybinds() {
+	for _, v := range g.DetailViews {
+		p, ok := v.(*ThreadView)
+		if !ok {
+			continue
+		}
+		p.SetSelectedFunc(func(row, col int) {
+			if tid := p.Selected(); tid != "" {
+				g.StackView.ShowThread(g, p.Pid(), tid)
+				g.ShowSystemView(StackPanel, g.StackView)
+			}
+		}).SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+			g.GlobalKeybind(event)
+			return event
+		})
+	}
+}
+
+func (g *Gui) SecurityViewKeybinds() {
+	for _, v := range g.DetailViews {
+		if p, ok := v.(*SecurityView); ok {
+			p.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+				g.GlobalKeybind(event)
+				return event
+			})
+		}
+	}
+}
+
+func (g *Gui) LimitsViewKeybinds() {
+	for _, v := range g.DetailViews {
+		if p, ok := v.(*LimitsView); ok {
+			p.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+				switch event.Rune() {
+				case 'e':
+					if l, ok := p.SelectedLimit(); ok {
+						g.EditLimit(p, p.Pid(), l.Limit)
+					}
+					return nil
+				}
+				g.GlobalKeybind(event)
+				return event
+			})
+		}
+	}
+}
+
+func (g *Gui) ProfileViewKeybinds() {
+	for _, v := range g.DetailViews {
+		p, ok := v.(*ProfileView)
+		if !ok {
+			continue
+		}
+		start := func(pids []proc.PID) {
+			g.Input("seconds:", "10", p, func(text string) {
+				seconds, err := strconv.Atoi(strings.TrimSpace(text))
+				if err != nil || seconds < 1 {
+					g.Message(fmt.Sprintf("invalid duration '%s'", text), p)
+					return
+				}
+				if err := p.Start(g, pids, time.Duration(seconds)*time.Second); err != nil {
+					g.Message(err.Error(), p)
+				}
+			})
+		}
+		p.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+			switch event.Rune() {
+			case 's':
+				if selected := g.ProcessManager.Selected(); selected != nil {
+					start([]proc.PID{selected.Pid})
+				}
+				return nil
+			case 'c':
+				if selected := g.ProcessManager.Selected(); selected != nil {
+					start(proc.GetDescendants(selected.Pid))
+				}
+				return nil
+			case 'w':
+				profile := p.Profile()
+				if profile == nil {
+					g.Message("nothing to save, profile a process first", p)
+					return nil
+				}
+				file := fmt.Sprintf("pst-%s.folded", profile.Pids[0])
+				g.Input("save to:", file, p, func(text string) {
+					if err := profile.SaveFolded(text); err != nil {
+						g.Message(err.Error(), p)
+						return
+					}
+					g.Message(fmt.Sprintf("saved %d stacks to %s", len(profile.Stacks), text), p)
+				})
+				return nil
+			}
+			g.GlobalKeybind(event)
+			return event
+		})
+	}
+}
+
+func (g *Gui) ProbeViewKeybinds() {
+	for _, v := range g.DetailViews {
+		if p, ok := v.(*ProbeView); ok {
+			p.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+				g.GlobalKeybind(event)
+				return event
+			})
+		}
+	}
+}
+
+// systemViewKeybinds binds the keys shared by the full screen process
+// lists: enter jumps to the process, r refreshes, q and Esc close.
+func (g *Gui) systemViewKeybinds(p *PidTable, refresh func()) {
+	p.SetDoneFunc(func(key tcell.Key) {
+		switch key {
+		case tcell.KeyEscape:
+			g.CloseSystemView()
+		}
+	}).SetSelectedFunc(func(row, col int) {
+		if pid := p.Selected(); pid != "" {
+			g.JumpToProcess(pid)
+		}
+	}).SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Rune() {
+		case 'r':
+			refresh()
+		case 'q':
+			g.CloseSystemView()
+			return nil
+		}
+		return event
+	})
+}
+
+func (g *Gui) ListenViewKeybinds() {
+	g.systemViewKeybinds(g.ListenView.PidTable, func() {
+		if err := g.ListenView.UpdateView(); err != nil {
+			g.Message(err.Error(), g.ListenView)
+		}
+	})
+}
+
+func (g *Gui) HolderViewKeybinds() {
+	g.systemViewKeybinds(g.HolderView.PidTable, func() {
+		if err := g.HolderView.Lookup(g.HolderView.Target()); err != nil {
+			g.Message(err.Error(), g.HolderView)
+		}
+	})
+}
+
+func (g *Gui) DeletedViewKeybinds() {
+	g.systemViewKeybinds(g.DeletedView.PidTable, func() {
+		g.DeletedView.UpdateView()
+	})
+}
+
+func (g *Gui) DStateViewKeybinds() {
+	g.systemViewKeybinds(g.DStateView.PidTable, func() {
+		g.DStateView.UpdateView(g.ProcessManager.GetStuckTasks())
+	})
+	capture := g.DStateView.GetInputCapture()
+	g.DStateView.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Rune() {
+		case 's':
+			if t, ok := g.DStateView.SelectedTask(); ok {
+				g.StackView.ShowThread(g, t.Pid, t.Tid)
+				g.StackView.from = DStatePanel
+				g.ShowSystemView(StackPanel, g.StackView)
+			}
+			return nil
+		}
+		return capture(event)
+	})
+}
+
+func (g *Gui) StackViewKeybinds() {
+	// return to the stuck task list when opened from it
+	closeView := func() {
+		if g.StackView.from == DStatePanel {
+			g.DStateView.UpdateView(g.ProcessManager.GetStuckTasks())
+			g.ShowSystemView(DStatePanel, g.DStateView)
+			return
+		}
+		g.CloseSystemView()
+	}
+	g.StackView.SetDoneFunc(func(key tcell.Key) {
+		switch key {
+		case tcell.KeyEscape:
+			closeView()
+		}
+	}).SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Rune() {
+		case 'q':
+			closeView()
+			return nil
+		}
+		return event
+	})
+}
+
 func (g *Gui) SetKeybinds() {
 	g.FilterInputKeybinds()
 	g.ProcessManagerKeybinds()
@@ -158,4 +558,19 @@ func (g *Gui) SetKeybinds() {
 	g.ProcessInfoViewKeybinds()
 	g.ProcessEnvViewKeybinds()
 	g.ProcessFileViewKeybinds()
+	g.SyscallStatViewKeybinds()
+	g.SyscallViewKeybinds()
+	g.NetViewKeybinds()
+	g.IPCViewKeybinds()
+	g.MemoryViewKeybinds()
+	g.ThreadViewKeybinds()
+	g.SecurityViewKeybinds()
+	g.LimitsViewKeybinds()
+	g.ProfileViewKeybinds()
+	g.ProbeViewKeybinds()
+	g.ListenViewKeybinds()
+	g.HolderViewKeybinds()
+	g.DeletedViewKeybinds()
+	g.StackViewKeybinds()
+	g.DStateViewKeybinds()
 }
@@ -0,0 +1,138 @@
+package gui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dixler/pst/gui/proc"
+	"github.com/gdamore/tcell"
+)
+
+var limitHeaders = []string{
+	"Limit",
+	"Soft",
+	"Hard",
+	"Units",
+	"Usage",
+	"Used%",
+}
+
+// LimitsView lists the resource limits of a process with their usage.
+type LimitsView struct {
+	*PidTable
+	pid    proc.PID
+	limits []proc.LimitUsage
+}
+
+func NewLimitsView() *LimitsView {
+	return &LimitsView{
+		PidTable: NewPidTable("process limits"),
+	}
+}
+
+// Pid is the process whose limits are shown.
+func (p *LimitsView) Pid() proc.PID {
+	return p.pid
+}
+
+// SelectedLimit returns the limit of the selected row.
+func (p *LimitsView) SelectedLimit() (proc.LimitUsage, bool) {
+	row, _ := p.GetSelection()
+	if row < 1 || row > len(p.limits) {
+		return proc.LimitUsage{}, false
+	}
+	return p.limits[row-1], true
+}
+
+func formatLimit(v uint64, units string) string {
+	if v == proc.Unlimited {
+		return "unlimited"
+	}
+	if units == "bytes" {
+		return formatBytes(int64(v))
+	}
+	return fmt.Sprint(v)
+}
+
+func (p *LimitsView) UpdateViewWithPid(g *Gui, pid proc.PID) {
+	fds := proc.CountFds
+	if n, ok := g.ProcessManager.GetFdCount(pid); ok {
+		fds = n
+	}
+	limits, err := proc.GetLimitUsage(pid, fds)
+
+	rows := make([][]string, 0, len(limits))
+	if err != nil {
+		rows = append(rows, []string{err.Error()})
+	}
+	for _, l := range limits {
+		usage, percent := "-", "-"
+		if l.Usage >= 0 {
+			usage = formatLimit(uint64(l.Usage), l.Units)
+		}
+		if pct := l.Percent(); pct >= 0 {
+			percent = fmt.Sprintf("%.1f", pct)
+		}
+		rows = append(rows, []string{
+			l.Name,
+			formatLimit(l.Soft, l.Units),
+			formatLimit(l.Hard, l.Units),
+			l.Units,
+			usage,
+			percent,
+		})
+	}
+
+	g.App.QueueUpdateDraw(func() {
+		p.pid = pid
+		p.limits = limits
+		p.SetRows(limitHeaders, rows, nil)
+		for i, l := range limits {
+			if !l.Near() {
+				continue
+			}
+			for j := range limitHeaders {
+				p.GetCell(i+1, j).SetTextColor(tcell.ColorRed)
+			}
+		}
+	})
+}
+
+// EditLimit asks for a new soft and hard value of l and sets it on pid
+// after confirmation.
+func (g *Gui) EditLimit(p *LimitsView, pid proc.PID, l proc.Limit) {
+	current := proc.FormatLimitValue(l.Soft) + " " + proc.FormatLimitValue(l.Hard)
+	label := fmt.Sprintf("%s (soft hard):", l.Name)
+	g.Input(label, current, p, func(text string) {
+		f := strings.Fields(text)
+		if len(f) != 2 {
+			g.Message("expected a soft and a hard limit, e.g. '1024 4096' or 'unlimited unlimited'", p)
+			return
+		}
+		soft, err := proc.ParseLimitValue(f[0])
+		if err != nil {
+			g.Message(err.Error(), p)
+			return
+		}
+		hard, err := proc.ParseLimitValue(f[1])
+		if err != nil {
+			g.Message(err.Error(), p)
+			return
+		}
+		if err := proc.ValidateLimit(l, soft, hard); err != nil {
+			g.Message(err.Error(), p)
+			return
+		}
+
+		message := fmt.Sprintf("Set %s of process %s to soft %s, hard %s?",
+			strings.ToLower(l.Name), pid, proc.FormatLimitValue(soft), proc.FormatLimitValue(hard))
+		g.Confirm(message, "set", p, func() {
+			if err := proc.SetLimit(pid, l, soft, hard); err != nil {
+				g.Message(fmt.Sprintf("unable to set %s: %s", strings.ToLower(l.Name), err), p)
+				return
+			}
+			g.Message(fmt.Sprintf("%s of process %s set to soft %s, hard %s",
+				l.Name, pid, proc.FormatLimitValue(soft), proc.FormatLimitValue(hard)), p)
+		})
+	})
+}
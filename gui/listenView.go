@@ -0,0 +1,57 @@
+package gui
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/dixler/pst/gui/proc"
+)
+
+var listenHeaders = []string{
+	"Proto",
+	"Address",
+	"Port",
+	"Pid",
+	"Cmd",
+}
+
+// ListenView lists the listening sockets of the system and the processes
+// owning them.
+type ListenView struct {
+	*PidTable
+}
+
+func NewListenView() *ListenView {
+	return &ListenView{
+		PidTable: NewPidTable("listening sockets"),
+	}
+}
+
+func (p *ListenView) UpdateView() error {
+	listeners, err := proc.ListeningSockets()
+	if err != nil {
+		return err
+	}
+
+	rows := make([][]string, 0, len(listeners))
+	pids := make([]proc.PID, 0, len(listeners))
+	for _, l := range listeners {
+		owners := l.Owners
+		if len(owners) == 0 {
+			owners = []proc.Fd{{}}
+		}
+		for _, o := range owners {
+			pid, cmd := "-", ""
+			if o.Pid != "" {
+				pid = o.Pid.String()
+				cmd = strings.TrimSpace(proc.GetCommand(o.Pid))
+			}
+			rows = append(rows, []string{l.Proto, l.Addr, strconv.Itoa(l.Port), pid, cmd})
+			pids = append(pids, o.Pid)
+		}
+	}
+	p.SetRows(listenHeaders, rows, pids)
+	p.Select(1, 0)
+
+	return nil
+}
@@ -0,0 +1,101 @@
+package gui
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/dixler/pst/gui/proc"
+	"github.com/rivo/tview"
+)
+
+// memorySorts are the orders the memory maps can be sorted by.
+var memorySorts = []struct {
+	name string
+	less func(a, b proc.Mapping) bool
+}{
+	{"size", func(a, b proc.Mapping) bool { return a.Size > b.Size }},
+	{"rss", func(a, b proc.Mapping) bool { return a.Rss > b.Rss }},
+	{"pss", func(a, b proc.Mapping) bool { return a.Pss > b.Pss }},
+	{"swap", func(a, b proc.Mapping) bool { return a.Swap > b.Swap }},
+	{"address", func(a, b proc.Mapping) bool { return a.Start < b.Start }},
+}
+
+// MemoryView lists the memory mappings of a process.
+type MemoryView struct {
+	*tview.TextView
+	lock  *sync.Mutex
+	order int
+}
+
+func NewMemoryView() *MemoryView {
+	p := &MemoryView{
+		TextView: tview.NewTextView().SetDynamicColors(true),
+		lock:     &sync.Mutex{},
+	}
+
+	p.SetTitleAlign(tview.AlignLeft).SetBorder(true)
+	p.SetWrap(false)
+	p.updateTitle()
+	return p
+}
+
+// NextSort cycles through the sort orders.
+func (p *MemoryView) NextSort() {
+	p.lock.Lock()
+	p.order = (p.order + 1) % len(memorySorts)
+	p.lock.Unlock()
+	p.updateTitle()
+}
+
+func (p *MemoryView) updateTitle() {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.SetTitle(fmt.Sprintf("process memory maps [by %s]", memorySorts[p.order].name))
+}
+
+func (p *MemoryView) UpdateViewWithPid(g *Gui, pid proc.PID) {
+	p.lock.Lock()
+	order := p.order
+	p.lock.Unlock()
+
+	text := ""
+	maps, err := proc.GetMappings(pid)
+	if err != nil {
+		text = err.Error()
+	} else {
+		less := memorySorts[order].less
+		sort.SliceStable(maps, func(i, j int) bool { return less(maps[i], maps[j]) })
+		text = renderMappings(maps)
+	}
+	if rollup, err := proc.GetMemRollup(pid); err == nil {
+		text = renderRollup(rollup) + "\n" + text
+	}
+
+	g.App.QueueUpdateDraw(func() {
+		p.SetText(text)
+	})
+}
+
+func renderRollup(m proc.Mapping) string {
+	return fmt.Sprintf("[yellow]total:[white] rss %s, pss %s, swap %s, dirty %s, thp %s\n",
+		formatBytes(m.Rss), formatBytes(m.Pss), formatBytes(m.Swap),
+		formatBytes(m.Dirty), formatBytes(m.AnonHuge))
+}
+
+func renderMappings(maps []proc.Mapping) string {
+	buf := bytes.Buffer{}
+	w := tabwriter.NewWriter(&buf, 0, 8, 2, ' ', 0)
+	fmt.Fprintf(w, "ADDRESS\tPERMS\tOFFSET\tSIZE\tRSS\tPSS\tSWAP\tDIRTY\tTHP\tMAPPING\n")
+	for _, m := range maps {
+		fmt.Fprintf(w, "%x-%x\t%s\t%x\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			m.Start, m.End, m.Perms, m.Offset,
+			formatBytes(m.Size), formatBytes(m.Rss), formatBytes(m.Pss),
+			formatBytes(m.Swap), formatBytes(m.Dirty), formatBytes(m.AnonHuge), m.Name())
+	}
+	w.Flush()
+
+	return highlightHeader(buf.String())
+}
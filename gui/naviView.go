@@ -33,6 +33,36 @@ func (n *NaviView) UpdateView(g *Gui) {
 			n.SetText(fmt.Sprintf("%s, %s, %s", moveNavi, switchNavi, helps[ProcessTreePanel]))
 		case ProcessFilePanel:
 			n.SetText(fmt.Sprintf("%s, %s, %s", moveNavi, switchNavi, helps[ProcessFilePanel]))
+		case SyscallPanel:
+			n.SetText(fmt.Sprintf("%s, %s, %s", moveNavi, switchNavi, helps[SyscallPanel]))
+		case SyscallStatPanel:
+			n.SetText(fmt.Sprintf("%s, %s, %s", moveNavi, switchNavi, helps[SyscallStatPanel]))
+		case NetPanel:
+			n.SetText(fmt.Sprintf("%s, %s, %s", moveNavi, switchNavi, helps[NetPanel]))
+		case IPCPanel:
+			n.SetText(fmt.Sprintf("%s, %s, %s", moveNavi, switchNavi, helps[IPCPanel]))
+		case MemoryPanel:
+			n.SetText(fmt.Sprintf("%s, %s, %s", moveNavi, switchNavi, helps[MemoryPanel]))
+		case ThreadPanel:
+			n.SetText(fmt.Sprintf("%s, %s, %s", moveNavi, switchNavi, helps[ThreadPanel]))
+		case SecurityPanel:
+			n.SetText(fmt.Sprintf("%s, %s", moveNavi, switchNavi))
+		case ProfilePanel:
+			n.SetText(fmt.Sprintf("%s, %s, %s", moveNavi, switchNavi, helps[ProfilePanel]))
+		case LimitsPanel:
+			n.SetText(fmt.Sprintf("%s, %s, %s", moveNavi, switchNavi, helps[LimitsPanel]))
+		case StackPanel:
+			n.SetText(fmt.Sprintf("%s, %s", moveNavi, helps[StackPanel]))
+		case ListenPanel:
+			n.SetText(fmt.Sprintf("%s, %s", moveNavi, helps[ListenPanel]))
+		case HolderPanel:
+			n.SetText(fmt.Sprintf("%s, %s", moveNavi, helps[HolderPanel]))
+		case DStatePanel:
+			n.SetText(fmt.Sprintf("%s, %s", moveNavi, helps[DStatePanel]))
+		case DeletedPanel:
+			n.SetText(fmt.Sprintf("%s, %s", moveNavi, helps[DeletedPanel]))
+		case ProbePanel:
+			n.SetText(fmt.Sprintf("%s, %s, %s", moveNavi, switchNavi, helps[ProbePanel]))
 		default:
 			n.SetText("")
 		}
@@ -46,9 +76,23 @@ var (
 
 var helps = map[int]string{
 	InputPanel:       ``,
-	ProcessesPanel:   `[red]K[white]: kill process`,
+	ProcessesPanel:   `[red]K[white]: kill process, [red]T[white]: trace subtree, [red]P[white]: trace cgroup, [red]M[white]: trace comm, [red]L[white]: listening sockets, [red]F[white]: find file holders, [red]D[white]: deleted open files, [red]C[white]: sort by cpu, [red]B[white]: cycle grouping, [red]N[white]: same namespace, [red]W[white]: kernel stacks, [red]S[white]: stuck tasks`,
 	ProcessInfoPanel: ``,
 	ProcessEnvPanel:  ``,
-	ProcessTreePanel: `[red]K[white]: kill process, [red]h[white]: collapse, [red]l[white]: expand, [red]enter[white]: expand toggle`,
+	ProcessTreePanel: `[red]K[white]: kill process, [red]T[white]: trace subtree, [red]U[white]: root at unit, [red]h[white]: collapse, [red]l[white]: expand, [red]enter[white]: expand toggle`,
 	ProcessFilePanel: ``,
+	ProbePanel:       ``,
+	NetPanel:         ``,
+	HolderPanel:      `[red]enter[white]: jump to process, [red]r[white]: refresh, [red]q/Esc[white]: close`,
+	DeletedPanel:     `[red]enter[white]: jump to process, [red]r[white]: refresh, [red]q/Esc[white]: close`,
+	IPCPanel:         `[red]enter[white]: jump to peer process`,
+	MemoryPanel:      `[red]o[white]: sort by size/rss/pss/swap/address`,
+	ThreadPanel:      `[red]enter[white]: kernel stack`,
+	LimitsPanel:      `[red]e[white]: edit limit`,
+	ProfilePanel:     `[red]s[white]: profile process, [red]c[white]: profile subtree, [red]w[white]: save folded stacks`,
+	DStatePanel:      `[red]enter[white]: jump to process, [red]s[white]: kernel stack, [red]r[white]: refresh, [red]q/Esc[white]: close`,
+	StackPanel:       `[red]q/Esc[white]: close`,
+	ListenPanel:      `[red]enter[white]: jump to process, [red]r[white]: refresh, [red]q/Esc[white]: close`,
+	SyscallStatPanel: `[red]o[white]: sort by count/time, [red]c[white]: include children`,
+	SyscallPanel:     `[red]s[white]: start/stop, [red]c[white]: include children, [red]p[white]: pause, [red]/[white]: filter, [red]w[white]: save`,
 }
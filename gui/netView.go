@@ -0,0 +1,77 @@
+package gui
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/dixler/pst/gui/proc"
+	"github.com/rivo/tview"
+)
+
+// NetView lists the current and recently closed tcp connections of a
+// process.
+type NetView struct {
+	*tview.TextView
+}
+
+func NewNetView() *NetView {
+	p := &NetView{
+		TextView: tview.NewTextView().SetDynamicColors(true),
+	}
+
+	p.SetTitleAlign(tview.AlignLeft).SetTitle("process connections").SetBorder(true)
+	p.SetWrap(false)
+	return p
+}
+
+func (p *NetView) UpdateViewWithPid(g *Gui, pid proc.PID) {
+	text := renderConns(g.ProcessManager.GetNetTrace(pid))
+
+	g.App.QueueUpdateDraw(func() {
+		p.SetText(text)
+	})
+}
+
+func renderConns(conns []proc.NetConn) string {
+	if len(conns) == 0 {
+		return "no tcp connections seen for this process yet"
+	}
+
+	// open connections first, then closed ones, most recent first
+	ordered := make([]proc.NetConn, 0, len(conns))
+	for i := len(conns) - 1; i >= 0; i-- {
+		if !conns[i].Closed() {
+			ordered = append(ordered, conns[i])
+		}
+	}
+	for i := len(conns) - 1; i >= 0; i-- {
+		if conns[i].Closed() {
+			ordered = append(ordered, conns[i])
+		}
+	}
+
+	buf := bytes.Buffer{}
+	w := tabwriter.NewWriter(&buf, 0, 8, 2, ' ', 0)
+	fmt.Fprintf(w, "STATE\tLOCAL\tREMOTE\tDURATION\tHISTORY\n")
+	for _, c := range ordered {
+		dur := c.Duration.Round(time.Millisecond).String()
+		if c.Start.IsZero() {
+			// opened before pst started
+			dur = "?"
+		} else if !c.Closed() {
+			dur = time.Since(c.Start).Round(time.Millisecond).String()
+		}
+		history := make([]string, 0, len(c.History))
+		for _, s := range c.History {
+			history = append(history, s.String())
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", c.State, c.Src, c.Dst,
+			dur, strings.Join(history, " > "))
+	}
+	w.Flush()
+
+	return highlightHeader(buf.String())
+}
@@ -0,0 +1,55 @@
+package gui
+
+import (
+	"github.com/dixler/pst/gui/proc"
+	"github.com/gdamore/tcell"
+	"github.com/rivo/tview"
+)
+
+// PidTable is a table whose rows each refer to a process.
+type PidTable struct {
+	*tview.Table
+	pids []proc.PID
+}
+
+func NewPidTable(title string) *PidTable {
+	p := &PidTable{
+		Table: tview.NewTable().Select(1, 0).SetFixed(1, 1).SetSelectable(true, false),
+	}
+	p.SetBorder(true).SetTitle(title).SetTitleAlign(tview.AlignLeft)
+	return p
+}
+
+// SetRows replaces the content of the table, pids[i] being the process
+// of rows[i] or empty if there is none.
+func (p *PidTable) SetRows(headers []string, rows [][]string, pids []proc.PID) {
+	table := p.Clear()
+	for i, h := range headers {
+		table.SetCell(0, i, &tview.TableCell{
+			Text:            h,
+			NotSelectable:   true,
+			Align:           tview.AlignLeft,
+			Color:           tcell.ColorYellow,
+			BackgroundColor: tcell.ColorDefault,
+		})
+	}
+	for i, row := range rows {
+		for j, c := range row {
+			table.SetCell(i+1, j, tview.NewTableCell(c))
+		}
+	}
+	p.pids = pids
+
+	if row, _ := p.GetSelection(); row > len(rows) {
+		p.Select(len(rows), 0)
+	}
+}
+
+// Selected returns the pid of the selected row, empty if there is none.
+func (p *PidTable) Selected() proc.PID {
+	row, _ := p.GetSelection()
+	if row < 1 || row > len(p.pids) {
+		return ""
+	}
+	return p.pids[row-1]
+}
@@ -0,0 +1,56 @@
+package gui
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/dixler/pst/gui/proc"
+	"github.com/rivo/tview"
+)
+
+// ProbeView renders the events of a user defined probe.
+type ProbeView struct {
+	*tview.TextView
+	source *proc.ProbeSource
+	err    error
+}
+
+func NewProbeView(title string, source *proc.ProbeSource, err error) *ProbeView {
+	p := &ProbeView{
+		TextView: tview.NewTextView().SetDynamicColors(true),
+		source:   source,
+		err:      err,
+	}
+
+	p.SetTitleAlign(tview.AlignLeft).SetTitle(title).SetBorder(true)
+	p.SetWrap(false)
+	return p
+}
+
+func (p *ProbeView) UpdateViewWithPid(g *Gui, pid proc.PID) {
+	text := ""
+	if p.err != nil {
+		text = p.err.Error()
+	} else {
+		text = renderProbe(p.source.Probe, p.source.Events(pid))
+	}
+
+	g.App.QueueUpdateDraw(func() {
+		p.SetText(text)
+		p.ScrollToEnd()
+	})
+}
+
+func renderProbe(probe proc.Probe, events []proc.ProbeData) string {
+	buf := bytes.Buffer{}
+	w := tabwriter.NewWriter(&buf, 0, 8, 2, ' ', 0)
+	fmt.Fprintf(w, "%s\n", strings.Join(probe.Fields, "\t"))
+	for _, e := range events {
+		fmt.Fprintf(w, "%s\n", strings.Join(e.Values, "\t"))
+	}
+	w.Flush()
+
+	return highlightHeader(buf.String())
+}
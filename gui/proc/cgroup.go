@@ -0,0 +1,202 @@
+package proc
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const cgroupRoot = "/sys/fs/cgroup"
+
+// Cgroup is a line of /proc/<pid>/cgroup. The cgroup v2 unified
+// hierarchy has id 0 and no controllers.
+type Cgroup struct {
+	Hierarchy   int
+	Controllers []string
+	Path        string
+}
+
+// Unified reports whether c is the cgroup v2 hierarchy.
+func (c Cgroup) Unified() bool {
+	return c.Hierarchy == 0 && len(c.Controllers) == 0
+}
+
+func ParseCgroups(s string) []Cgroup {
+	var cgroups []Cgroup
+	for _, line := range strings.Split(strings.TrimSpace(s), "\n") {
+		// hierarchy-ID:controller-list:cgroup-path
+		f := strings.SplitN(line, ":", 3)
+		if len(f) != 3 {
+			continue
+		}
+		id, err := strconv.Atoi(f[0])
+		if err != nil {
+			continue
+		}
+		var controllers []string
+		if f[1] != "" {
+			controllers = strings.Split(f[1], ",")
+		}
+		cgroups = append(cgroups, Cgroup{Hierarchy: id, Controllers: controllers, Path: f[2]})
+	}
+	return cgroups
+}
+
+func GetCgroups(pid PID) ([]Cgroup, error) {
+	s, err := readProcPath(pid, "cgroup")
+	if err != nil {
+		return nil, err
+	}
+	return ParseCgroups(s), nil
+}
+
+// CgroupPath picks the path naming the cgroup of a process: the unified
+// hierarchy on cgroup v2 and the systemd hierarchy on v1. Hybrid setups
+// may leave processes at the root of the unified hierarchy.
+func CgroupPath(cgroups []Cgroup) string {
+	for _, c := range cgroups {
+		if c.Unified() && (c.Path != "/" || len(cgroups) == 1) {
+			return c.Path
+		}
+	}
+	for _, want := range []string{"name=systemd", "cpu"} {
+		for _, c := range cgroups {
+			for _, controller := range c.Controllers {
+				if controller == want {
+					return c.Path
+				}
+			}
+		}
+	}
+	if len(cgroups) > 0 {
+		return cgroups[0].Path
+	}
+	return ""
+}
+
+func GetCgroupPath(pid PID) (string, error) {
+	cgroups, err := GetCgroups(pid)
+	if err != nil {
+		return "", err
+	}
+	return CgroupPath(cgroups), nil
+}
+
+// GetCgroupID returns the id bpftrace's cgroup builtin reports for pid, the
+// inode of its cgroup v2 directory.
+func GetCgroupID(pid PID) (uint64, error) {
+	cgroups, err := GetCgroups(pid)
+	if err != nil {
+		return 0, err
+	}
+	dir, ok := cgroupDir(cgroups, "")
+	if !ok {
+		return 0, fmt.Errorf("process %s is not in a cgroup v2 hierarchy", pid)
+	}
+	info, err := os.Stat(dir)
+	if err != nil {
+		return 0, err
+	}
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, fmt.Errorf("unable to read the inode of '%s'", dir)
+	}
+	return uint64(st.Ino), nil
+}
+
+// cgroupDir returns the directory of the cgroup in the hierarchy of
+// controller under /sys/fs/cgroup, controller being "" for cgroup v2.
+func cgroupDir(cgroups []Cgroup, controller string) (string, bool) {
+	for _, c := range cgroups {
+		if controller == "" && c.Unified() {
+			// hybrid setups mount v2 on unified/
+			if _, err := os.Stat(path.Join(cgroupRoot, "cgroup.controllers")); err == nil {
+				return path.Join(cgroupRoot, c.Path), true
+			}
+			return path.Join(cgroupRoot, "unified", c.Path), true
+		}
+		for _, cc := range c.Controllers {
+			if controller != "" && cc == controller {
+				// named hierarchies are mounted without the name= prefix
+				dir := strings.TrimPrefix(strings.Join(c.Controllers, ","), "name=")
+				return path.Join(cgroupRoot, dir, c.Path), true
+			}
+		}
+	}
+	return "", false
+}
+
+// CgroupStats are the usage counters of a cgroup, -1 when unknown.
+type CgroupStats struct {
+	CPU    time.Duration
+	Memory int64
+	Pids   int64
+}
+
+func readCgroupInt(dir, file string) (int64, bool) {
+	b, err := ioutil.ReadFile(path.Join(dir, file))
+	if err != nil {
+		return 0, false
+	}
+	n, err := strconv.ParseInt(strings.TrimSpace(string(b)), 10, 64)
+	return n, err == nil
+}
+
+// readCgroupKey reads key from a flat keyed file such as cpu.stat.
+func readCgroupKey(dir, file, key string) (int64, bool) {
+	f, err := os.Open(path.Join(dir, file))
+	if err != nil {
+		return 0, false
+	}
+	defer f.Close()
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		fields := strings.Fields(sc.Text())
+		if len(fields) == 2 && fields[0] == key {
+			n, err := strconv.ParseInt(fields[1], 10, 64)
+			return n, err == nil
+		}
+	}
+	return 0, false
+}
+
+// GetCgroupStats reads the cpu, memory and pids counters of the cgroups
+// of a process from the v2 hierarchy, falling back to the v1 controllers.
+func GetCgroupStats(cgroups []Cgroup) CgroupStats {
+	stats := CgroupStats{CPU: -1, Memory: -1, Pids: -1}
+
+	if dir, ok := cgroupDir(cgroups, ""); ok {
+		if n, ok := readCgroupKey(dir, "cpu.stat", "usage_usec"); ok {
+			stats.CPU = time.Duration(n) * time.Microsecond
+		}
+		if n, ok := readCgroupInt(dir, "memory.current"); ok {
+			stats.Memory = n
+		}
+		if n, ok := readCgroupInt(dir, "pids.current"); ok {
+			stats.Pids = n
+		}
+	}
+
+	if dir, ok := cgroupDir(cgroups, "cpuacct"); ok && stats.CPU < 0 {
+		if n, ok := readCgroupInt(dir, "cpuacct.usage"); ok {
+			stats.CPU = time.Duration(n)
+		}
+	}
+	if dir, ok := cgroupDir(cgroups, "memory"); ok && stats.Memory < 0 {
+		if n, ok := readCgroupInt(dir, "memory.usage_in_bytes"); ok {
+			stats.Memory = n
+		}
+	}
+	if dir, ok := cgroupDir(cgroups, "pids"); ok && stats.Pids < 0 {
+		if n, ok := readCgroupInt(dir, "pids.current"); ok {
+			stats.Pids = n
+		}
+	}
+	return stats
+}
@@ -0,0 +1,90 @@
+package proc
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Container identifies the container a process runs in.
+type Container struct {
+	// Runtime is "docker", "containerd", "crio", "podman" or, for a
+	// kubernetes pod whose runtime can't be told from the path, "cri".
+	Runtime string
+	ID      string
+	// PodUID is set for containers of kubernetes pods.
+	PodUID string
+}
+
+// ShortID is the container id as printed by docker ps.
+func (c Container) ShortID() string {
+	if len(c.ID) > 12 {
+		return c.ID[:12]
+	}
+	return c.ID
+}
+
+var (
+	// systemd cgroup driver, e.g. docker-<id>.scope, cri-containerd-<id>.scope
+	scopeRe = regexp.MustCompile(`^(docker|cri-containerd|crio|libpod)-([0-9a-f]{64})\.scope$`)
+	// cgroupfs driver, e.g. /docker/<id>, /libpod_parent/libpod-<id>
+	idRe = regexp.MustCompile(`^(?:libpod-)?([0-9a-f]{64})$`)
+	// kubepods-burstable-pod<uid>.slice or pod<uid>
+	podRe = regexp.MustCompile(`^(?:kubepods(?:-[a-z]+)?-)?pod([0-9a-f_-]{36})(?:\.slice)?$`)
+)
+
+var scopeRuntimes = map[string]string{
+	"docker":         "docker",
+	"cri-containerd": "containerd",
+	"crio":           "crio",
+	"libpod":         "podman",
+}
+
+// ParseContainer recognizes the cgroup paths container runtimes and the
+// kubelet create, e.g.
+//
+//	/system.slice/docker-<id>.scope
+//	/docker/<id>
+//	/kubepods/burstable/pod<uid>/<id>
+//	/kubepods.slice/kubepods-burstable.slice/kubepods-burstable-pod<uid>.slice/cri-containerd-<id>.scope
+func ParseContainer(cgroupPath string) (Container, bool) {
+	var c Container
+	parent := ""
+	for _, elem := range strings.Split(cgroupPath, "/") {
+		if m := podRe.FindStringSubmatch(elem); m != nil {
+			// the systemd driver escapes the dashes of the uid
+			c.PodUID = strings.ReplaceAll(m[1], "_", "-")
+		} else if m := scopeRe.FindStringSubmatch(elem); m != nil {
+			c.Runtime, c.ID = scopeRuntimes[m[1]], m[2]
+		} else if m := idRe.FindStringSubmatch(elem); m != nil {
+			c.ID = m[1]
+			switch {
+			case strings.HasPrefix(elem, "libpod-") || parent == "libpod_parent":
+				c.Runtime = "podman"
+			case parent == "docker":
+				c.Runtime = "docker"
+			}
+		}
+		parent = elem
+	}
+	if c.ID == "" {
+		return Container{}, false
+	}
+	if c.Runtime == "" && c.PodUID != "" {
+		c.Runtime = "cri"
+	}
+	return c, true
+}
+
+// GetContainer returns the container pid runs in, if any.
+func GetContainer(pid PID) (Container, bool) {
+	cgroups, err := GetCgroups(pid)
+	if err != nil {
+		return Container{}, false
+	}
+	for _, cg := range cgroups {
+		if c, ok := ParseContainer(cg.Path); ok {
+			return c, true
+		}
+	}
+	return Container{}, false
+}
@@ -0,0 +1,146 @@
+package proc
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const cpuSampleInterval = time.Second
+
+// ReadCPUTotal reads the jiffies spent by all cpus from the "cpu" line of
+// /proc/stat and the number of cpus.
+func ReadCPUTotal() (uint64, int, error) {
+	f, err := os.Open("/proc/stat")
+	if err != nil {
+		return 0, 0, err
+	}
+	defer f.Close()
+
+	var total uint64
+	cpus := 0
+	found := false
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		fields := strings.Fields(sc.Text())
+		if len(fields) == 0 || !strings.HasPrefix(fields[0], "cpu") {
+			continue
+		}
+		if fields[0] != "cpu" {
+			cpus++
+			continue
+		}
+		found = true
+		// user nice system idle iowait irq softirq steal, guest times
+		// are already accounted in user and nice
+		for i := 1; i < len(fields) && i <= 8; i++ {
+			n, _ := strconv.ParseUint(fields[i], 10, 64)
+			total += n
+		}
+	}
+	if !found {
+		return 0, 0, fmt.Errorf("no cpu line in /proc/stat")
+	}
+	return total, cpus, sc.Err()
+}
+
+// CPUSampler periodically computes the cpu usage of every process and
+// thread from the utime/stime deltas against /proc/stat, 100 being one
+// cpu fully used.
+type CPUSampler struct {
+	lock        *sync.RWMutex
+	prevTotal   uint64
+	prevProcs   map[ProcKey]uint64
+	prevThreads map[ProcKey]uint64
+	procs       map[PID]float64
+	threads     map[PID]float64
+}
+
+func NewCPUSampler() *CPUSampler {
+	s := &CPUSampler{
+		lock:    &sync.RWMutex{},
+		procs:   make(map[PID]float64),
+		threads: make(map[PID]float64),
+	}
+	go func() {
+		s.sample()
+		for range time.Tick(cpuSampleInterval) {
+			s.sample()
+		}
+	}()
+	return s
+}
+
+// readTicks reads utime+stime of the stat files matched by pattern,
+// keyed by the pid (or tid) in the path.
+func readTicks(pattern string) map[ProcKey]uint64 {
+	files, _ := filepath.Glob(pattern)
+	ticks := make(map[ProcKey]uint64, len(files))
+	for _, f := range files {
+		b, err := ioutil.ReadFile(f)
+		if err != nil {
+			continue
+		}
+		st, err := ParseStat(string(b))
+		if err != nil {
+			continue
+		}
+		pid := PID(path.Base(path.Dir(f)))
+		ticks[ProcKey{Pid: pid, Start: st.StartTime}] = st.Utime + st.Stime
+	}
+	return ticks
+}
+
+func usage(prev, cur map[ProcKey]uint64, elapsed uint64, cpus int) map[PID]float64 {
+	result := make(map[PID]float64, len(cur))
+	for key, ticks := range cur {
+		p, ok := prev[key]
+		if !ok || elapsed == 0 || ticks < p {
+			continue
+		}
+		result[key.Pid] = float64(ticks-p) / float64(elapsed) * float64(cpus) * 100
+	}
+	return result
+}
+
+func (s *CPUSampler) sample() {
+	total, cpus, err := ReadCPUTotal()
+	if err != nil {
+		return
+	}
+	procs := readTicks("/proc/[0-9]*/stat")
+	threads := readTicks("/proc/[0-9]*/task/[0-9]*/stat")
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	elapsed := total - s.prevTotal
+	if s.prevTotal == 0 || total < s.prevTotal {
+		elapsed = 0
+	}
+	s.procs = usage(s.prevProcs, procs, elapsed, cpus)
+	s.threads = usage(s.prevThreads, threads, elapsed, cpus)
+	s.prevTotal, s.prevProcs, s.prevThreads = total, procs, threads
+}
+
+// Process returns the cpu usage of pid over the last sample interval.
+func (s *CPUSampler) Process(pid PID) (float64, bool) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	u, ok := s.procs[pid]
+	return u, ok
+}
+
+// Thread returns the cpu usage of tid over the last sample interval.
+func (s *CPUSampler) Thread(tid PID) (float64, bool) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	u, ok := s.threads[tid]
+	return u, ok
+}
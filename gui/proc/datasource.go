@@ -2,9 +2,12 @@ package proc
 
 import (
 	"bufio"
+	"errors"
 	"os"
 	"os/exec"
 	"strconv"
+	"sync"
+	"time"
 )
 
 type PID string
@@ -24,8 +27,19 @@ func (p PID) Int() int {
 type Datasource[T any] struct {
 	Get       func(pid PID) (T, error)
 	GetStream func() (chan PID, chan T, error)
+	// SetFilter restarts the tracer with a program limited to filter.
+	SetFilter func(filter Filter) error
+	// Close stops the tracer and closes the stream.
+	Close func()
+	// Err returns why the tracer is not running, nil while it is.
+	Err func() error
 }
 
+// attachTimeout bounds how long a restarted tracer may take to attach
+// before it is assumed to be running, bpftrace may print nothing on
+// stdout until the first event.
+const attachTimeout = 5 * time.Second
+
 /*
 TODO
 ==================
@@ -58,10 +72,117 @@ Goroutine 15 (running) created at:
 ==================
 */
 
-func NewSource[T any](program string,
+func NewSource[T any](program string, filter Filter,
 	process func(line string) (PID, T, error),
 	get ...func(pid PID) (T, error),
 ) (Datasource[T], error) {
+	return NewParserSource(program, filter, func() func(line string) (PID, T, error) {
+		return process
+	}, get...)
+}
+
+// NewParserSource is NewSource for parsers keeping state across lines,
+// newParser being called for each started tracer so that a tracer being
+// replaced never feeds the parser of the new one.
+func NewParserSource[T any](program string, filter Filter,
+	newParser func() func(line string) (PID, T, error),
+	get ...func(pid PID) (T, error),
+) (Datasource[T], error) {
+
+	pidCh := make(chan PID, 500)
+	dataCh := make(chan T, 500)
+
+	lock := &sync.Mutex{}
+	send := &sync.Mutex{}
+	closed := false
+	stop, attached, err := runSource(applyFilter(program, filter), newParser(), send, pidCh, dataCh)
+
+	// a tracer that can't start (no bpftrace, no privileges) leaves the
+	// datasource empty rather than failing pst.
+	status := err
+	if err == nil {
+		go func() {
+			err := waitAttached(attached)
+			lock.Lock()
+			status = err
+			lock.Unlock()
+		}()
+	}
+
+	ds := Datasource[T]{
+		GetStream: func() (chan PID, chan T, error) {
+			return pidCh, dataCh, nil
+		},
+		SetFilter: func(filter Filter) error {
+			lock.Lock()
+			defer lock.Unlock()
+
+			if closed {
+				return errors.New("datasource is closed")
+			}
+
+			// Keep the current tracer until the new one has attached, so a
+			// filter bpftrace rejects leaves the previous trace running.
+			next, attached, err := runSource(applyFilter(program, filter), newParser(), send, pidCh, dataCh)
+			if err != nil {
+				return err
+			}
+			if err := waitAttached(attached); err != nil {
+				next()
+				return err
+			}
+			stop()
+			stop = next
+			status = nil
+			return nil
+		},
+		Close: func() {
+			lock.Lock()
+			defer lock.Unlock()
+
+			if closed {
+				return
+			}
+			closed = true
+			stop()
+			close(pidCh)
+			close(dataCh)
+		},
+		Err: func() error {
+			lock.Lock()
+			defer lock.Unlock()
+			return status
+		},
+	}
+	if len(get) > 0 {
+		ds.Get = get[0]
+	}
+	return ds, err
+}
+
+// waitAttached waits for a tracer started by runSource to attach, assuming
+// it did if it is still running after attachTimeout.
+func waitAttached(attached <-chan error) error {
+	select {
+	case err := <-attached:
+		return err
+	case <-time.After(attachTimeout):
+		return nil
+	}
+}
+
+// runSource starts bpftrace and feeds parsed lines into pidCh/dataCh.
+// attached receives nil once bpftrace has attached its probes, or an error
+// if it exits before that. Each pid/data pair is sent under send so that
+// two tracers briefly running side by side never split a pair.
+// The returned func kills the tracer and waits until its reader is gone.
+func runSource[T any](program string,
+	process func(line string) (PID, T, error),
+	send *sync.Mutex,
+	pidCh chan PID, dataCh chan T,
+) (func(), <-chan error, error) {
+
+	attached := make(chan error, 1)
 
 	cmd := exec.Command("bpftrace", "-e", program)
 	cmd.Env = append(cmd.Env, "BPFTRACE_STRLEN=200")
@@ -70,33 +191,29 @@ func NewSource[T any](program string,
 	if err != nil {
 		panic(err.Error())
 	}
+	if err := cmd.Start(); err != nil {
+		attached <- err
+		return func() {}, attached, err
+	}
 	rd := bufio.NewReader(out)
 
-	pidCh := make(chan PID, 500)
-	dataCh := make(chan T, 500)
-	done := make(chan bool, 1)
-
-	go func() {
-		err = cmd.Run()
-		if err != nil {
-			//fmt.Printf("%v\n", err)
-		}
-		<-done
-		close(done)
-		cmd.Process.Kill()
-	}()
+	done := make(chan bool)
 
 	go func() {
+		defer close(done)
 		first := true
 		for {
 			str, err := rd.ReadString('\n')
 			if err != nil {
 				//fmt.Println("Read Error:", err)
-				done <- true
+				if first {
+					attached <- errors.New("bpftrace exited before attaching probes")
+				}
 				return
 			}
 			if first {
 				first = false
+				attached <- nil
 				continue
 			}
 
@@ -106,15 +223,17 @@ func NewSource[T any](program string,
 					return
 				}
 
+				send.Lock()
 				pidCh <- pid
 				dataCh <- d
+				send.Unlock()
 			}(str)
 		}
 	}()
 
-	return Datasource[T]{
-		GetStream: func() (chan PID, chan T, error) {
-			return pidCh, dataCh, err
-		},
-	}, nil
+	return func() {
+		cmd.Process.Kill()
+		<-done
+		cmd.Wait()
+	}, attached, nil
 }
@@ -0,0 +1,89 @@
+package proc
+
+import (
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+const deletedSuffix = " (deleted)"
+
+// DeletedFile is an fd whose file has been unlinked, still holding its
+// disk space until the fd is closed.
+type DeletedFile struct {
+	Fd
+	Dev  uint64
+	Ino  uint64
+	Size int64
+}
+
+// Path is the path the file had before it was deleted.
+func (d DeletedFile) Path() string {
+	return strings.TrimSuffix(d.Target, deletedSuffix)
+}
+
+// DeletedFiles finds the deleted files pid holds open, sized by the
+// blocks they use on disk.
+func DeletedFiles(pid PID) ([]DeletedFile, error) {
+	fds, err := Fds(pid)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]DeletedFile, 0, 4)
+	for _, fd := range fds {
+		// memfds are not backed by a disk
+		if !strings.HasSuffix(fd.Target, deletedSuffix) || strings.HasPrefix(fd.Target, "/memfd:") {
+			continue
+		}
+		info, err := os.Stat(path.Join("/proc", pid.String(), "fd", strconv.Itoa(fd.Fd)))
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		st := info.Sys().(*syscall.Stat_t)
+		files = append(files, DeletedFile{
+			Fd:   fd,
+			Dev:  uint64(st.Dev),
+			Ino:  uint64(st.Ino),
+			Size: int64(st.Blocks) * 512,
+		})
+	}
+	return files, nil
+}
+
+// AllDeletedFiles finds the deleted files held open by every process,
+// largest first.
+func AllDeletedFiles() []DeletedFile {
+	files := make([]DeletedFile, 0, 64)
+	for _, pid := range Pids() {
+		f, err := DeletedFiles(pid)
+		if err != nil {
+			continue
+		}
+		files = append(files, f...)
+	}
+	sort.SliceStable(files, func(i, j int) bool {
+		return files[i].Size > files[j].Size
+	})
+	return files
+}
+
+// DeletedSize sums the space held by files, counting files held by
+// several fds once.
+func DeletedSize(files []DeletedFile) int64 {
+	type key struct{ dev, ino uint64 }
+	seen := make(map[key]bool)
+	var total int64
+	for _, f := range files {
+		k := key{f.Dev, f.Ino}
+		if seen[k] {
+			continue
+		}
+		seen[k] = true
+		total += f.Size
+	}
+	return total
+}
@@ -0,0 +1,121 @@
+package proc
+
+import (
+	"io/ioutil"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const dstateSampleInterval = time.Second
+
+// StuckTask is a task in uninterruptible sleep (D state).
+type StuckTask struct {
+	Pid   PID
+	Tid   PID
+	Comm  string
+	Wchan string
+	// Since is when the task was first seen in D state without being
+	// scheduled in between.
+	Since time.Time
+	// Before reports that the task was already stuck when sampling
+	// started, Since being a lower bound.
+	Before bool
+}
+
+type dstate struct {
+	task     StuckTask
+	switches uint64
+}
+
+// DStateSampler periodically looks for tasks in D state and tracks for
+// how long they have been stuck.
+type DStateSampler struct {
+	lock    *sync.RWMutex
+	sampled bool
+	tasks   map[ProcKey]dstate
+}
+
+func NewDStateSampler() *DStateSampler {
+	s := &DStateSampler{
+		lock:  &sync.RWMutex{},
+		tasks: make(map[ProcKey]dstate),
+	}
+	go func() {
+		s.sample()
+		for range time.Tick(dstateSampleInterval) {
+			s.sample()
+		}
+	}()
+	return s
+}
+
+// taskSwitches returns the context switches of a task, which only change
+// when it is scheduled.
+func taskSwitches(pid, tid PID) uint64 {
+	status, err := GetTaskStatus(pid, tid)
+	if err != nil {
+		return 0
+	}
+	v, _ := strconv.ParseUint(status["voluntary_ctxt_switches"], 10, 64)
+	n, _ := strconv.ParseUint(status["nonvoluntary_ctxt_switches"], 10, 64)
+	return v + n
+}
+
+func (s *DStateSampler) sample() {
+	now := time.Now()
+	files, _ := filepath.Glob("/proc/[0-9]*/task/[0-9]*/stat")
+
+	s.lock.RLock()
+	prev := s.tasks
+	s.lock.RUnlock()
+
+	tasks := make(map[ProcKey]dstate)
+	for _, f := range files {
+		b, err := ioutil.ReadFile(f)
+		if err != nil {
+			continue
+		}
+		st, err := ParseStat(string(b))
+		if err != nil || st.State != "D" {
+			continue
+		}
+		taskDir := path.Dir(f)
+		tid := PID(path.Base(taskDir))
+		pid := PID(path.Base(path.Dir(path.Dir(taskDir))))
+		key := ProcKey{Pid: tid, Start: st.StartTime}
+
+		d := dstate{
+			task:     StuckTask{Pid: pid, Tid: tid, Comm: st.Comm, Since: now, Before: !s.sampled},
+			switches: taskSwitches(pid, tid),
+		}
+		if p, ok := prev[key]; ok && p.switches == d.switches {
+			d.task.Since, d.task.Before = p.task.Since, p.task.Before
+		}
+		if wchan, err := ioutil.ReadFile(path.Join(taskDir, "wchan")); err == nil && string(wchan) != "0" {
+			d.task.Wchan = strings.TrimSpace(string(wchan))
+		}
+		tasks[key] = d
+	}
+
+	s.lock.Lock()
+	s.tasks = tasks
+	s.sampled = true
+	s.lock.Unlock()
+}
+
+// Stuck returns the tasks in D state, longest stuck first.
+func (s *DStateSampler) Stuck() []StuckTask {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	tasks := make([]StuckTask, 0, len(s.tasks))
+	for _, d := range s.tasks {
+		tasks = append(tasks, d.task)
+	}
+	sort.Slice(tasks, func(i, j int) bool { return tasks[i].Since.Before(tasks[j].Since) })
+	return tasks
+}
@@ -0,0 +1,117 @@
+package proc
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Fd is an open file descriptor of a process.
+type Fd struct {
+	Pid    PID
+	Fd     int
+	Target string
+}
+
+// Fds reads the fd table of pid from /proc/<pid>/fd.
+func Fds(pid PID) ([]Fd, error) {
+	dir := path.Join("/proc", pid.String(), "fd")
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	fds := make([]Fd, 0, len(files))
+	for _, f := range files {
+		n, err := strconv.Atoi(f.Name())
+		if err != nil {
+			continue
+		}
+		target, err := os.Readlink(path.Join(dir, f.Name()))
+		if err != nil {
+			continue
+		}
+		fds = append(fds, Fd{Pid: pid, Fd: n, Target: target})
+	}
+	sort.Slice(fds, func(i, j int) bool { return fds[i].Fd < fds[j].Fd })
+	return fds, nil
+}
+
+// Pids lists the processes currently in /proc.
+func Pids() []PID {
+	files, err := filepath.Glob("/proc/*")
+	if err != nil {
+		panic("glob panicked")
+	}
+	pids := make([]PID, 0, len(files))
+	for _, f := range files {
+		candidate := path.Base(f)
+		if _, err := strconv.Atoi(candidate); err != nil {
+			continue
+		}
+		pids = append(pids, PID(candidate))
+	}
+	return pids
+}
+
+// AllFds reads the fd tables of every process, skipping the ones we are
+// not allowed to read.
+func AllFds() []Fd {
+	fds := make([]Fd, 0, 4096)
+	for _, pid := range Pids() {
+		f, err := Fds(pid)
+		if err != nil {
+			continue
+		}
+		fds = append(fds, f...)
+	}
+	return fds
+}
+
+// fdIndexTTL bounds how often FdIndex rescans every fd table.
+const fdIndexTTL = 2 * time.Second
+
+var fdIndex = struct {
+	sync.Mutex
+	at    time.Time
+	index map[string][]Fd
+}{}
+
+// FdIndex maps fd targets (e.g. "socket:[1234]", "pipe:[42]") to the fds
+// of every process holding them. The index is cached for fdIndexTTL.
+func FdIndex() map[string][]Fd {
+	fdIndex.Lock()
+	defer fdIndex.Unlock()
+
+	if fdIndex.index != nil && time.Since(fdIndex.at) < fdIndexTTL {
+		return fdIndex.index
+	}
+
+	index := make(map[string][]Fd)
+	for _, fd := range AllFds() {
+		index[fd.Target] = append(index[fd.Target], fd)
+	}
+	fdIndex.index = index
+	fdIndex.at = time.Now()
+	return index
+}
+
+// Inode parses anonymous inode fd targets like "socket:[1234]", returning
+// the kind ("socket", "pipe", ...) and the inode.
+func Inode(target string) (string, uint64, bool) {
+	i := strings.Index(target, ":[")
+	if i < 0 || !strings.HasSuffix(target, "]") {
+		return "", 0, false
+	}
+	ino, err := strconv.ParseUint(target[i+2:len(target)-1], 10, 64)
+	if err != nil {
+		return "", 0, false
+	}
+	return target[:i], ino, true
+}
@@ -0,0 +1,199 @@
+package proc
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	fdSampleInterval = 5 * time.Second
+	// fdSampleSize samples are kept per process, 5 minutes at the
+	// default interval.
+	fdSampleSize = 60
+
+	// a process is suspected to leak when its fd count never decreased
+	// over at least leakMinSamples samples and grew by leakMinGrowth.
+	leakMinSamples = 6
+	leakMinGrowth  = 5
+)
+
+// FdKind classifies an fd target as "file", "socket", "pipe", "anon" or
+// "other".
+func FdKind(target string) string {
+	switch {
+	case strings.HasPrefix(target, "/"):
+		return "file"
+	case strings.HasPrefix(target, "socket:"):
+		return "socket"
+	case strings.HasPrefix(target, "pipe:"):
+		return "pipe"
+	case strings.HasPrefix(target, "anon_inode:"):
+		return "anon"
+	}
+	return "other"
+}
+
+type FdSample struct {
+	Time  time.Time
+	Kinds map[string]int
+}
+
+func (s FdSample) Total() int {
+	total := 0
+	for _, n := range s.Kinds {
+		total += n
+	}
+	return total
+}
+
+// FdTrend is the fd count history of a process.
+type FdTrend struct {
+	Samples []FdSample
+	// Limit is the soft RLIMIT_NOFILE, 0 when unknown.
+	Limit uint64
+}
+
+// Growth returns how much each fd kind grew over the samples.
+func (t FdTrend) Growth() map[string]int {
+	growth := make(map[string]int)
+	if len(t.Samples) < 2 {
+		return growth
+	}
+	first, last := t.Samples[0], t.Samples[len(t.Samples)-1]
+	for k, n := range last.Kinds {
+		growth[k] = n - first.Kinds[k]
+	}
+	for k, n := range first.Kinds {
+		if _, ok := last.Kinds[k]; !ok {
+			growth[k] = -n
+		}
+	}
+	return growth
+}
+
+// GrowingKinds returns the fd kinds that grew, largest growth first.
+func (t FdTrend) GrowingKinds() []string {
+	growth := t.Growth()
+	kinds := make([]string, 0, len(growth))
+	for k, n := range growth {
+		if n > 0 {
+			kinds = append(kinds, k)
+		}
+	}
+	sort.Slice(kinds, func(i, j int) bool { return growth[kinds[i]] > growth[kinds[j]] })
+	return kinds
+}
+
+// Leaking reports whether the fd count grew monotonically.
+func (t FdTrend) Leaking() bool {
+	if len(t.Samples) < leakMinSamples {
+		return false
+	}
+	for i := 1; i < len(t.Samples); i++ {
+		if t.Samples[i].Total() < t.Samples[i-1].Total() {
+			return false
+		}
+	}
+	return t.Samples[len(t.Samples)-1].Total()-t.Samples[0].Total() >= leakMinGrowth
+}
+
+// countFds counts the fds of pid by kind.
+func countFds(pid PID) (map[string]int, error) {
+	dir := path.Join("/proc", pid.String(), "fd")
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	kinds := make(map[string]int)
+	for _, f := range files {
+		target, err := os.Readlink(path.Join(dir, f.Name()))
+		if err != nil {
+			continue
+		}
+		kinds[FdKind(target)]++
+	}
+	return kinds, nil
+}
+
+// FdSampler periodically samples the fd counts of every process.
+type FdSampler struct {
+	lock    *sync.RWMutex
+	samples map[ProcKey][]FdSample
+}
+
+func NewFdSampler() *FdSampler {
+	s := &FdSampler{
+		lock:    &sync.RWMutex{},
+		samples: make(map[ProcKey][]FdSample),
+	}
+	go func() {
+		s.sample()
+		for range time.Tick(fdSampleInterval) {
+			s.sample()
+		}
+	}()
+	return s
+}
+
+func (s *FdSampler) sample() {
+	now := time.Now()
+	seen := make(map[ProcKey]FdSample)
+	for _, pid := range Pids() {
+		key, err := GetProcKey(pid)
+		if err != nil {
+			continue
+		}
+		kinds, err := countFds(pid)
+		if err != nil {
+			continue
+		}
+		seen[key] = FdSample{Time: now, Kinds: kinds}
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for key := range s.samples {
+		if _, ok := seen[key]; !ok {
+			delete(s.samples, key)
+		}
+	}
+	for key, sample := range seen {
+		samples := append(s.samples[key], sample)
+		if len(samples) > fdSampleSize {
+			samples = samples[len(samples)-fdSampleSize:]
+		}
+		s.samples[key] = samples
+	}
+}
+
+// Latest returns the last sampled fd count of key.
+func (s *FdSampler) Latest(key ProcKey) (int, bool) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	samples := s.samples[key]
+	if len(samples) == 0 {
+		return 0, false
+	}
+	return samples[len(samples)-1].Total(), true
+}
+
+func (s *FdSampler) Trend(pid PID) FdTrend {
+	trend := FdTrend{}
+	if l, ok := GetLimit(pid, "Max open files"); ok {
+		trend.Limit = l.Soft
+	}
+
+	key, err := GetProcKey(pid)
+	if err != nil {
+		return trend
+	}
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	trend.Samples = append([]FdSample(nil), s.samples[key]...)
+	return trend
+}
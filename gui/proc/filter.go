@@ -0,0 +1,82 @@
+package proc
+
+import (
+	"fmt"
+	"strings"
+)
+
+// filterToken marks where a Filter predicate is spliced into a bpftrace
+// program. It is a comment so unfiltered programs stay valid as written.
+const filterToken = "/*filter*/"
+
+// Filter restricts a tracer to a subset of processes. The zero value
+// traces every process.
+type Filter struct {
+	Pids []PID
+	// Cgroup is a cgroup v2 id, as returned by GetCgroupID.
+	Cgroup uint64
+	Comm   string
+}
+
+func (f Filter) IsEmpty() bool {
+	return len(f.Pids) == 0 && f.Cgroup == 0 && f.Comm == ""
+}
+
+// Predicate renders the filter as a bpftrace predicate, e.g.
+// `/(pid == 1 || pid == 2) && comm == "sshd"/`.
+func (f Filter) Predicate() string {
+	if f.IsEmpty() {
+		return ""
+	}
+
+	conds := make([]string, 0, 3)
+	if len(f.Pids) > 0 {
+		pids := make([]string, 0, len(f.Pids))
+		for _, p := range f.Pids {
+			pids = append(pids, fmt.Sprintf("pid == %d", p.Int()))
+		}
+		conds = append(conds, "("+strings.Join(pids, " || ")+")")
+	}
+	if f.Cgroup != 0 {
+		conds = append(conds, fmt.Sprintf("cgroup == %d", f.Cgroup))
+	}
+	if f.Comm != "" {
+		conds = append(conds, "comm == "+bpftraceString(f.Comm))
+	}
+	return "/" + strings.Join(conds, " && ") + "/"
+}
+
+// commLen is the size of the comm of a task without its trailing NUL.
+const commLen = 15
+
+// bpftraceString quotes a comm as a bpftrace string literal. Comms are at
+// most commLen bytes, longer names are cut as the kernel does.
+func bpftraceString(s string) string {
+	if len(s) > commLen {
+		s = s[:commLen]
+	}
+	b := strings.Builder{}
+	b.WriteByte('"')
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '"' || c == '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case c == '\n':
+			b.WriteString(`\n`)
+		case c == '\t':
+			b.WriteString(`\t`)
+		case c < 0x20 || c == 0x7f:
+			// bpftrace has no escape for other control characters
+			b.WriteByte('?')
+		default:
+			b.WriteByte(c)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+func applyFilter(program string, filter Filter) string {
+	return strings.ReplaceAll(program, filterToken, filter.Predicate())
+}
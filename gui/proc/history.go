@@ -0,0 +1,142 @@
+package proc
+
+import (
+	"bufio"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	historyInterval = time.Second
+	// historySize samples are kept per process, 5 minutes at the
+	// default interval.
+	historySize = 300
+)
+
+// HistorySample is a reading of the resource usage of a process. Values
+// that could not be read are -1.
+type HistorySample struct {
+	Time time.Time
+	// CPU is the cpu usage, 100 being one cpu fully used.
+	CPU float64
+	// Rss is in bytes.
+	Rss int64
+	Fds int
+	// IORate is the bytes per second read from and written to storage.
+	IORate float64
+}
+
+type ioReading struct {
+	bytes uint64
+	at    time.Time
+}
+
+// ReadIOBytes returns read_bytes+write_bytes from /proc/<pid>/io.
+func ReadIOBytes(pid PID) (uint64, error) {
+	f, err := os.Open("/proc/" + pid.String() + "/io")
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	var total uint64
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		name, value, ok := strings.Cut(sc.Text(), ":")
+		if !ok || (name != "read_bytes" && name != "write_bytes") {
+			continue
+		}
+		n, err := strconv.ParseUint(strings.TrimSpace(value), 10, 64)
+		if err != nil {
+			return 0, err
+		}
+		total += n
+	}
+	return total, sc.Err()
+}
+
+// History keeps the recent resource usage of every process, keyed by
+// ProcKey so a reused pid starts with an empty history.
+type History struct {
+	lock    *sync.RWMutex
+	cpu     *CPUSampler
+	fds     *FdSampler
+	io      map[ProcKey]ioReading
+	samples map[ProcKey][]HistorySample
+}
+
+func NewHistory(cpu *CPUSampler, fds *FdSampler) *History {
+	h := &History{
+		lock:    &sync.RWMutex{},
+		cpu:     cpu,
+		fds:     fds,
+		io:      make(map[ProcKey]ioReading),
+		samples: make(map[ProcKey][]HistorySample),
+	}
+	go func() {
+		for range time.Tick(historyInterval) {
+			h.sample()
+		}
+	}()
+	return h
+}
+
+func (h *History) sample() {
+	now := time.Now()
+	pageSize := int64(os.Getpagesize())
+	seen := make(map[ProcKey]HistorySample)
+	io := make(map[ProcKey]ioReading)
+	for _, pid := range Pids() {
+		st, err := GetStat(pid)
+		if err != nil {
+			continue
+		}
+		key := ProcKey{Pid: pid, Start: st.StartTime}
+		sample := HistorySample{Time: now, CPU: -1, Rss: st.Rss * pageSize, Fds: -1, IORate: -1}
+		if u, ok := h.cpu.Process(pid); ok {
+			sample.CPU = u
+		}
+		if n, ok := h.fds.Latest(key); ok {
+			sample.Fds = n
+		}
+		if b, err := ReadIOBytes(pid); err == nil {
+			io[key] = ioReading{bytes: b, at: now}
+			if prev, ok := h.io[key]; ok && b >= prev.bytes {
+				if elapsed := now.Sub(prev.at).Seconds(); elapsed > 0 {
+					sample.IORate = float64(b-prev.bytes) / elapsed
+				}
+			}
+		}
+		seen[key] = sample
+	}
+
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	h.io = io
+	for key := range h.samples {
+		if _, ok := seen[key]; !ok {
+			delete(h.samples, key)
+		}
+	}
+	for key, sample := range seen {
+		samples := append(h.samples[key], sample)
+		if len(samples) > historySize {
+			samples = samples[len(samples)-historySize:]
+		}
+		h.samples[key] = samples
+	}
+}
+
+// Samples returns the history of pid, oldest first.
+func (h *History) Samples(pid PID) []HistorySample {
+	key, err := GetProcKey(pid)
+	if err != nil {
+		return nil
+	}
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+	return append([]HistorySample(nil), h.samples[key]...)
+}
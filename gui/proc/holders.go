@@ -0,0 +1,107 @@
+package proc
+
+import (
+	"bufio"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// FileHolder is a process using a file, through an fd or as its working
+// directory, root or executable.
+type FileHolder struct {
+	Fd
+	// Use is "fd", "cwd", "root" or "exe", Fd.Fd is -1 unless Use is "fd".
+	Use string
+}
+
+// IsMountPoint reports whether p is listed as a mount point in
+// /proc/self/mountinfo.
+func IsMountPoint(p string) bool {
+	f, err := os.Open("/proc/self/mountinfo")
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		// id parent major:minor root mountpoint ...
+		fields := strings.Fields(sc.Text())
+		if len(fields) > 4 && unescapeMount(fields[4]) == p {
+			return true
+		}
+	}
+	return false
+}
+
+func unescapeMount(s string) string {
+	r := strings.NewReplacer(`\040`, " ", `\011`, "\t", `\012`, "\n", `\134`, `\`)
+	return r.Replace(s)
+}
+
+// FileHolders finds the processes holding target open, like fuser. If
+// target is a mount point every file on that filesystem matches, if it is
+// a device node the device itself matches, and if it no longer exists the
+// "(deleted)" fd targets with that path match.
+func FileHolders(target string) ([]FileHolder, error) {
+	target, err := filepath.Abs(target)
+	if err != nil {
+		return nil, err
+	}
+
+	var match func(link string) bool
+	info, err := os.Stat(target)
+	switch {
+	case os.IsNotExist(err):
+		match = func(link string) bool {
+			t, err := os.Readlink(link)
+			return err == nil && (t == target || t == target+" (deleted)")
+		}
+	case err != nil:
+		return nil, err
+	default:
+		st := info.Sys().(*syscall.Stat_t)
+		mount := info.IsDir() && IsMountPoint(target)
+		device := info.Mode()&(os.ModeDevice|os.ModeCharDevice) != 0
+		match = func(link string) bool {
+			i, err := os.Stat(link)
+			if err != nil {
+				return false
+			}
+			s := i.Sys().(*syscall.Stat_t)
+			switch {
+			case mount:
+				return s.Dev == st.Dev
+			case device && i.Mode()&(os.ModeDevice|os.ModeCharDevice) != 0:
+				return s.Rdev == st.Rdev
+			}
+			return s.Dev == st.Dev && s.Ino == st.Ino
+		}
+	}
+
+	holders := make([]FileHolder, 0, 16)
+	for _, pid := range Pids() {
+		for _, use := range []string{"cwd", "root", "exe"} {
+			link := path.Join("/proc", pid.String(), use)
+			if match(link) {
+				t, _ := os.Readlink(link)
+				holders = append(holders, FileHolder{Fd: Fd{Pid: pid, Fd: -1, Target: t}, Use: use})
+			}
+		}
+
+		fds, err := Fds(pid)
+		if err != nil {
+			continue
+		}
+		for _, fd := range fds {
+			if match(path.Join("/proc", pid.String(), "fd", strconv.Itoa(fd.Fd))) {
+				holders = append(holders, FileHolder{Fd: fd, Use: "fd"})
+			}
+		}
+	}
+	return holders, nil
+}
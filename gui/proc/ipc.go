@@ -0,0 +1,103 @@
+package proc
+
+import (
+	"bufio"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// IPCEnd is one end of a pipe or unix socket.
+type IPCEnd struct {
+	Fd
+	// Mode is "read" or "write" for pipes, empty for sockets.
+	Mode string
+}
+
+// IPCLink is a pipe or unix socket of a process and the fds of other
+// processes holding the other end.
+type IPCLink struct {
+	IPCEnd
+	Kind  string
+	Peers []IPCEnd
+}
+
+// FdFlags reads the open flags of fd from /proc/<pid>/fdinfo/<fd>.
+func FdFlags(pid PID, fd int) (int, error) {
+	f, err := os.Open(path.Join("/proc", pid.String(), "fdinfo", strconv.Itoa(fd)))
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		kv := strings.SplitN(sc.Text(), ":", 2)
+		if len(kv) == 2 && kv[0] == "flags" {
+			flags, err := strconv.ParseInt(strings.TrimSpace(kv[1]), 8, 64)
+			return int(flags), err
+		}
+	}
+	return 0, sc.Err()
+}
+
+func pipeMode(fd Fd) string {
+	flags, err := FdFlags(fd.Pid, fd.Fd)
+	if err != nil {
+		return ""
+	}
+	switch flags & os.O_WRONLY {
+	case os.O_WRONLY:
+		return "write"
+	}
+	return "read"
+}
+
+// IPCLinks matches the pipes and unix sockets of pid against the fd
+// tables of every other process.
+func IPCLinks(pid PID) ([]IPCLink, error) {
+	fds, err := Fds(pid)
+	if err != nil {
+		return nil, err
+	}
+
+	index := FdIndex()
+	links := make([]IPCLink, 0, 8)
+	for _, fd := range fds {
+		kind, _, ok := Inode(fd.Target)
+		if !ok || kind != "pipe" {
+			continue
+		}
+		link := IPCLink{
+			IPCEnd: IPCEnd{Fd: fd, Mode: pipeMode(fd)},
+			Kind:   kind,
+		}
+		for _, peer := range index[fd.Target] {
+			if peer.Pid == pid {
+				continue
+			}
+			link.Peers = append(link.Peers, IPCEnd{Fd: peer, Mode: pipeMode(peer)})
+		}
+		links = append(links, link)
+	}
+
+	sockets, err := ProcessSockets(pid)
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range sockets {
+		if !strings.HasPrefix(s.Proto, "unix") {
+			continue
+		}
+		link := IPCLink{
+			IPCEnd: IPCEnd{Fd: s.Fd},
+			Kind:   s.Proto,
+		}
+		for _, peer := range s.Peers {
+			link.Peers = append(link.Peers, IPCEnd{Fd: peer})
+		}
+		links = append(links, link)
+	}
+	return links, nil
+}
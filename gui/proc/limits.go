@@ -0,0 +1,278 @@
+package proc
+
+import (
+	"bufio"
+	"os"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Unlimited is the value of a limit set to "unlimited".
+const Unlimited = ^uint64(0)
+
+// Limit is a resource limit from /proc/<pid>/limits.
+type Limit struct {
+	Name  string
+	Soft  uint64
+	Hard  uint64
+	Units string
+}
+
+// limitRe matches "Max open files            1024                 524288               files"
+var limitRe = regexp.MustCompile(`^(.+?)\s+(\d+|unlimited)\s+(\d+|unlimited)\s*(\S*)\s*$`)
+
+// GetLimits reads the resource limits of pid, in file order.
+func GetLimits(pid PID) ([]Limit, error) {
+	f, err := os.Open(path.Join("/proc", pid.String(), "limits"))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	limits := make([]Limit, 0, 16)
+	sc := bufio.NewScanner(f)
+	sc.Scan() // header
+	for sc.Scan() {
+		m := limitRe.FindStringSubmatch(strings.TrimSpace(sc.Text()))
+		if m == nil {
+			continue
+		}
+		// the regexp only lets numbers and "unlimited" through
+		soft, _ := ParseLimitValue(m[2])
+		hard, _ := ParseLimitValue(m[3])
+		limits = append(limits, Limit{
+			Name:  m[1],
+			Soft:  soft,
+			Hard:  hard,
+			Units: m[4],
+		})
+	}
+	return limits, sc.Err()
+}
+
+// GetLimit returns the limit called name (e.g. "Max open files").
+func GetLimit(pid PID, name string) (Limit, bool) {
+	limits, err := GetLimits(pid)
+	if err != nil {
+		return Limit{}, false
+	}
+	for _, l := range limits {
+		if l.Name == name {
+			return l, true
+		}
+	}
+	return Limit{}, false
+}
+
+// NearLimitPercent is the usage from which a limit is considered close
+// to be reached.
+const NearLimitPercent = 80
+
+// LimitUsage is a limit with the current usage of the resource it
+// limits, in the units of the limit. Usage is -1 when pst doesn't
+// measure the resource.
+type LimitUsage struct {
+	Limit
+	Usage int64
+}
+
+// Percent returns the usage relative to the soft limit, -1 when the
+// usage is unknown or the limit unlimited.
+func (u LimitUsage) Percent() float64 {
+	if u.Usage < 0 || u.Soft == Unlimited || u.Soft == 0 {
+		return -1
+	}
+	return float64(u.Usage) / float64(u.Soft) * 100
+}
+
+func (u LimitUsage) Near() bool {
+	return u.Percent() >= NearLimitPercent
+}
+
+// userThreadsTTL bounds how often userThreads rescans every process.
+const userThreadsTTL = 2 * time.Second
+
+var userThreadsCache = struct {
+	sync.Mutex
+	at      time.Time
+	threads map[string]int64
+}{}
+
+// userThreads counts the threads of every real uid, the resource
+// RLIMIT_NPROC limits. The counts are cached for userThreadsTTL.
+func userThreads() map[string]int64 {
+	userThreadsCache.Lock()
+	defer userThreadsCache.Unlock()
+
+	if userThreadsCache.threads != nil && time.Since(userThreadsCache.at) < userThreadsTTL {
+		return userThreadsCache.threads
+	}
+
+	threads := make(map[string]int64)
+	for _, pid := range Pids() {
+		status, err := GetStatus(pid)
+		if err != nil {
+			continue
+		}
+		n, _ := strconv.ParseInt(status["Threads"], 10, 64)
+		threads[realUid(status)] += n
+	}
+	userThreadsCache.threads = threads
+	userThreadsCache.at = time.Now()
+	return threads
+}
+
+func realUid(status map[string]string) string {
+	if f := strings.Fields(status["Uid"]); len(f) > 0 {
+		return f[0]
+	}
+	return ""
+}
+
+// statusBytes parses a "1234 kB" status value.
+func statusBytes(status map[string]string, key string) int64 {
+	f := strings.Fields(status[key])
+	if len(f) == 0 {
+		return -1
+	}
+	n, err := strconv.ParseInt(f[0], 10, 64)
+	if err != nil {
+		return -1
+	}
+	return n * 1024
+}
+
+// CountFds and UnknownFds can be passed as fd count to GetLimitUsage, to
+// count the fds of the process or to leave the open files usage unknown.
+const (
+	CountFds   = -1
+	UnknownFds = -2
+)
+
+// GetLimitUsage returns the limits of pid with the usage of the resources
+// pst can measure. fds is the fd count of pid, or CountFds or UnknownFds.
+func GetLimitUsage(pid PID, fds int) ([]LimitUsage, error) {
+	limits, err := GetLimits(pid)
+	if err != nil {
+		return nil, err
+	}
+	status, err := GetStatus(pid)
+	if err != nil {
+		return nil, err
+	}
+
+	usages := make([]LimitUsage, 0, len(limits))
+	for _, l := range limits {
+		usage := int64(-1)
+		switch l.Name {
+		case "Max open files":
+			if fds == CountFds {
+				if kinds, err := countFds(pid); err == nil {
+					fds = 0
+					for _, n := range kinds {
+						fds += n
+					}
+				}
+			}
+			if fds >= 0 {
+				usage = int64(fds)
+			}
+		case "Max processes":
+			// RLIMIT_NPROC counts the threads of the real user
+			usage = userThreads()[realUid(status)]
+		case "Max resident set":
+			usage = statusBytes(status, "VmRSS")
+		case "Max locked memory":
+			usage = statusBytes(status, "VmLck")
+		case "Max address space":
+			usage = statusBytes(status, "VmSize")
+		case "Max stack size":
+			usage = statusBytes(status, "VmStk")
+		case "Max pending signals":
+			// "queued/limit"
+			if q, _, ok := strings.Cut(status["SigQ"], "/"); ok {
+				if n, err := strconv.ParseInt(q, 10, 64); err == nil {
+					usage = n
+				}
+			}
+		}
+		usages = append(usages, LimitUsage{Limit: l, Usage: usage})
+	}
+	return usages, nil
+}
+
+// NearLimits returns the limits of pid whose usage is at least
+// NearLimitPercent. fds is as for GetLimitUsage.
+func NearLimits(pid PID, fds int) []LimitUsage {
+	usages, err := GetLimitUsage(pid, fds)
+	if err != nil {
+		return nil
+	}
+	var near []LimitUsage
+	for _, u := range usages {
+		if u.Near() {
+			near = append(near, u)
+		}
+	}
+	return near
+}
+
+// limitSampleInterval is how often every process is checked against its
+// limits.
+const limitSampleInterval = 5 * time.Second
+
+// LimitSampler periodically looks for processes close to one of their
+// limits, so the process list doesn't read the limits of every row.
+type LimitSampler struct {
+	lock *sync.RWMutex
+	fds  *FdSampler
+	near map[PID]bool
+}
+
+func NewLimitSampler(fds *FdSampler) *LimitSampler {
+	s := &LimitSampler{
+		lock: &sync.RWMutex{},
+		fds:  fds,
+		near: make(map[PID]bool),
+	}
+	go func() {
+		s.sample()
+		for range time.Tick(limitSampleInterval) {
+			s.sample()
+		}
+	}()
+	return s
+}
+
+func (s *LimitSampler) sample() {
+	near := make(map[PID]bool)
+	for _, pid := range Pids() {
+		key, err := GetProcKey(pid)
+		if err != nil {
+			continue
+		}
+		// counting fds is left to the fd sampler
+		fds, ok := s.fds.Latest(key)
+		if !ok {
+			fds = UnknownFds
+		}
+		if len(NearLimits(pid, fds)) > 0 {
+			near[pid] = true
+		}
+	}
+
+	s.lock.Lock()
+	s.near = near
+	s.lock.Unlock()
+}
+
+// Near reports whether pid was close to one of its limits when last sampled.
+func (s *LimitSampler) Near(pid PID) bool {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.near[pid]
+}
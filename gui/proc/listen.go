@@ -0,0 +1,74 @@
+package proc
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Listener is a listening tcp socket or a bound, unconnected udp socket.
+type Listener struct {
+	Socket
+	Addr   string
+	Port   int
+	Owners []Fd
+}
+
+// ListeningSockets lists the listening sockets of every network namespace
+// and the processes holding them.
+func ListeningSockets() ([]Listener, error) {
+	// read the socket tables once per network namespace
+	seen := make(map[string]bool)
+	sockets := make(map[uint64]Socket)
+	for _, pid := range append([]PID{"self"}, Pids()...) {
+		ns, err := os.Readlink(path.Join("/proc", pid.String(), "ns", "net"))
+		if err != nil || seen[ns] {
+			continue
+		}
+		seen[ns] = true
+
+		for _, proto := range []string{"tcp", "tcp6", "udp", "udp6"} {
+			if err := readInetSockets(pid, proto, sockets); err != nil && !os.IsNotExist(err) && pid == "self" {
+				return nil, err
+			}
+		}
+	}
+
+	index := FdIndex()
+	listeners := make([]Listener, 0, 64)
+	for ino, s := range sockets {
+		if s.State != TCPListen.String() && s.State != "UNCONN" {
+			continue
+		}
+		host, port, err := net.SplitHostPort(s.Local)
+		if err != nil {
+			continue
+		}
+		p, _ := strconv.Atoi(port)
+		if p == 0 {
+			continue
+		}
+		listeners = append(listeners, Listener{
+			Socket: s,
+			Addr:   host,
+			Port:   p,
+			Owners: index[fmt.Sprintf("socket:[%d]", ino)],
+		})
+	}
+
+	sort.Slice(listeners, func(i, j int) bool {
+		a, b := listeners[i], listeners[j]
+		if a.Port != b.Port {
+			return a.Port < b.Port
+		}
+		if a.Proto != b.Proto {
+			return a.Proto < b.Proto
+		}
+		return strings.Compare(a.Addr, b.Addr) < 0
+	})
+	return listeners, nil
+}
@@ -0,0 +1,145 @@
+package proc
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// Mapping is a memory mapping from /proc/<pid>/smaps, sizes in bytes.
+type Mapping struct {
+	Start    uint64
+	End      uint64
+	Perms    string
+	Offset   uint64
+	Dev      string
+	Inode    uint64
+	Path     string
+	Size     int64
+	Rss      int64
+	Pss      int64
+	Swap     int64
+	Dirty    int64
+	AnonHuge int64
+}
+
+// Name is the backing file of the mapping, its kind ([heap], [stack],
+// ...) or [anon].
+func (m Mapping) Name() string {
+	if m.Path == "" {
+		return "[anon]"
+	}
+	return m.Path
+}
+
+// GetMappings parses /proc/<pid>/smaps.
+func GetMappings(pid PID) ([]Mapping, error) {
+	return readSmaps(pid, "smaps")
+}
+
+// GetMemRollup parses /proc/<pid>/smaps_rollup, the sum of every mapping.
+func GetMemRollup(pid PID) (Mapping, error) {
+	m, err := readSmaps(pid, "smaps_rollup")
+	if err != nil {
+		return Mapping{}, err
+	}
+	if len(m) != 1 {
+		return Mapping{}, fmt.Errorf("unexpected smaps_rollup content")
+	}
+	return m[0], nil
+}
+
+func readSmaps(pid PID, name string) ([]Mapping, error) {
+	f, err := os.Open(path.Join("/proc", pid.String(), name))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	maps := make([]Mapping, 0, 64)
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+	for sc.Scan() {
+		fields := strings.Fields(sc.Text())
+		if len(fields) == 0 {
+			continue
+		}
+
+		if !strings.HasSuffix(fields[0], ":") {
+			// start-end perms offset dev inode [path]
+			m, err := parseMapping(sc.Text(), fields)
+			if err != nil {
+				return nil, err
+			}
+			maps = append(maps, m)
+			continue
+		}
+
+		if len(maps) == 0 || len(fields) != 3 || fields[2] != "kB" {
+			continue
+		}
+		kb, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		m := &maps[len(maps)-1]
+		v := kb * 1024
+		switch fields[0] {
+		case "Size:":
+			m.Size = v
+		case "Rss:":
+			m.Rss = v
+		case "Pss:":
+			m.Pss = v
+		case "Swap:":
+			m.Swap = v
+		case "Shared_Dirty:", "Private_Dirty:":
+			m.Dirty += v
+		case "AnonHugePages:":
+			m.AnonHuge = v
+		}
+	}
+	return maps, sc.Err()
+}
+
+func parseMapping(line string, fields []string) (Mapping, error) {
+	if len(fields) < 5 {
+		return Mapping{}, fmt.Errorf("unable to parse mapping '%s'", line)
+	}
+	r := strings.SplitN(fields[0], "-", 2)
+	if len(r) != 2 {
+		return Mapping{}, fmt.Errorf("unable to parse mapping '%s'", line)
+	}
+	start, err := strconv.ParseUint(r[0], 16, 64)
+	if err != nil {
+		return Mapping{}, fmt.Errorf("unable to parse mapping '%s'", line)
+	}
+	end, err := strconv.ParseUint(r[1], 16, 64)
+	if err != nil {
+		return Mapping{}, fmt.Errorf("unable to parse mapping '%s'", line)
+	}
+	offset, _ := strconv.ParseUint(fields[2], 16, 64)
+	inode, _ := strconv.ParseUint(fields[4], 10, 64)
+
+	m := Mapping{
+		Start:  start,
+		End:    end,
+		Perms:  fields[1],
+		Offset: offset,
+		Dev:    fields[3],
+		Inode:  inode,
+	}
+	// the path may contain spaces, take the rest of the line
+	if len(fields) > 5 {
+		rest := line
+		for _, f := range fields[:5] {
+			rest = strings.TrimLeft(rest, " \t")
+			rest = rest[len(f):]
+		}
+		m.Path = strings.TrimSpace(rest)
+	}
+	return m, nil
+}
@@ -0,0 +1,90 @@
+package proc
+
+import "strings"
+
+// filterKeys match the "key:value" words of a process filter against
+// process attributes, an empty value matching any process having the
+// attribute.
+var filterKeys = map[string]func(pid PID, value string) bool{
+	"container": func(pid PID, value string) bool {
+		c, ok := GetContainer(pid)
+		return ok && strings.HasPrefix(c.ID, value)
+	},
+	"runtime": func(pid PID, value string) bool {
+		c, ok := GetContainer(pid)
+		return ok && strings.HasPrefix(c.Runtime, value)
+	},
+	"pod": func(pid PID, value string) bool {
+		c, ok := GetContainer(pid)
+		return ok && c.PodUID != "" && strings.HasPrefix(c.PodUID, value)
+	},
+	"unit": func(pid PID, value string) bool {
+		unit := GetUnit(pid)
+		return unit != "" && strings.HasPrefix(unit, value)
+	},
+	"netns": func(pid PID, value string) bool {
+		return SameNamespace(pid, PID(value), "net")
+	},
+	"mntns": func(pid PID, value string) bool {
+		return SameNamespace(pid, PID(value), "mnt")
+	},
+	"pidns": func(pid PID, value string) bool {
+		return SameNamespace(pid, PID(value), "pid")
+	},
+	"cap": func(pid PID, value string) bool {
+		sec, err := GetSecurity(pid)
+		if err != nil {
+			return false
+		}
+		if value == "" {
+			return sec.Caps["CapEff"] != 0
+		}
+		return sec.Caps["CapEff"].HasName(value)
+	},
+	"seccomp": func(pid PID, value string) bool {
+		sec, err := GetSecurity(pid)
+		if err != nil {
+			return false
+		}
+		if value == "" {
+			return sec.Seccomp != "" && sec.Seccomp != "disabled"
+		}
+		return sec.Seccomp == value
+	},
+	"nnp": func(pid PID, value string) bool {
+		sec, err := GetSecurity(pid)
+		if err != nil {
+			return false
+		}
+		return sec.NoNewPrivs == (value == "" || value == "1")
+	},
+	"label": func(pid PID, value string) bool {
+		label := GetLabel(pid)
+		return label != "" && strings.Contains(label, value)
+	},
+}
+
+// MatchProcess reports whether the process matches filter. Words of the
+// form key:value with a known key must all match, the rest of the filter
+// must be contained in the command.
+func MatchProcess(pid PID, command, filter string) bool {
+	words := strings.Fields(filter)
+	rest := make([]string, 0, len(words))
+	keyed := false
+	for _, w := range words {
+		key, value, ok := strings.Cut(w, ":")
+		match, known := filterKeys[key]
+		if !ok || !known {
+			rest = append(rest, w)
+			continue
+		}
+		keyed = true
+		if !match(pid, value) {
+			return false
+		}
+	}
+	if !keyed {
+		return strings.Contains(command, filter)
+	}
+	return strings.Contains(command, strings.Join(rest, " "))
+}
@@ -0,0 +1,66 @@
+package proc
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"strings"
+)
+
+// NamespaceTypes are the entries of /proc/<pid>/ns pst reads.
+var NamespaceTypes = []string{"cgroup", "ipc", "mnt", "net", "pid", "time", "user", "uts"}
+
+// GetNamespace returns the inode of the ns namespace of pid.
+func GetNamespace(pid PID, ns string) (uint64, error) {
+	target, err := os.Readlink(path.Join("/proc", pid.String(), "ns", ns))
+	if err != nil {
+		return 0, err
+	}
+	// e.g. "net:[4026531840]"
+	var inode uint64
+	if _, err := fmt.Sscanf(target, ns+":[%d]", &inode); err != nil {
+		return 0, fmt.Errorf("unable to parse namespace '%s'", target)
+	}
+	return inode, nil
+}
+
+// GetNamespaces returns the namespace inodes of pid by type, leaving out
+// those the kernel doesn't support or that can't be read.
+func GetNamespaces(pid PID) map[string]uint64 {
+	namespaces := make(map[string]uint64, len(NamespaceTypes))
+	for _, ns := range NamespaceTypes {
+		if inode, err := GetNamespace(pid, ns); err == nil {
+			namespaces[ns] = inode
+		}
+	}
+	return namespaces
+}
+
+// GetNSpid returns the pids of pid from the outermost to the innermost
+// pid namespace it is in, the last one being the pid seen in its own
+// namespace.
+func GetNSpid(pid PID) ([]PID, error) {
+	status, err := GetStatus(pid)
+	if err != nil {
+		return nil, err
+	}
+	nspid, ok := status["NSpid"]
+	if !ok {
+		return []PID{pid}, nil
+	}
+	var pids []PID
+	for _, p := range strings.Fields(nspid) {
+		pids = append(pids, PID(p))
+	}
+	return pids, nil
+}
+
+// SameNamespace reports whether a and b share their ns namespace.
+func SameNamespace(a, b PID, ns string) bool {
+	na, err := GetNamespace(a, ns)
+	if err != nil {
+		return false
+	}
+	nb, err := GetNamespace(b, ns)
+	return err == nil && na == nb
+}
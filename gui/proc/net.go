@@ -0,0 +1,220 @@
+package proc
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const netLogSize = 200
+
+type TCPState int
+
+// tcp states as in include/net/tcp_states.h
+const (
+	TCPEstablished TCPState = iota + 1
+	TCPSynSent
+	TCPSynRecv
+	TCPFinWait1
+	TCPFinWait2
+	TCPTimeWait
+	TCPClose
+	TCPCloseWait
+	TCPLastAck
+	TCPListen
+	TCPClosing
+	TCPNewSynRecv
+)
+
+var tcpStateNames = map[TCPState]string{
+	TCPEstablished: "ESTABLISHED",
+	TCPSynSent:     "SYN_SENT",
+	TCPSynRecv:     "SYN_RECV",
+	TCPFinWait1:    "FIN_WAIT1",
+	TCPFinWait2:    "FIN_WAIT2",
+	TCPTimeWait:    "TIME_WAIT",
+	TCPClose:       "CLOSE",
+	TCPCloseWait:   "CLOSE_WAIT",
+	TCPLastAck:     "LAST_ACK",
+	TCPListen:      "LISTEN",
+	TCPClosing:     "CLOSING",
+	TCPNewSynRecv:  "NEW_SYN_RECV",
+}
+
+func (s TCPState) String() string {
+	if name, ok := tcpStateNames[s]; ok {
+		return name
+	}
+	return fmt.Sprintf("UNKNOWN(%d)", int(s))
+}
+
+// NetData is a tcp socket state transition, or an accept when Accept is
+// set. State changes mostly happen in softirq context, so the PID is only
+// known for transitions made by the process itself (connect, listen) and
+// sockets are tracked by Sock to attribute the rest.
+type NetData struct {
+	Sock     string
+	Accept   bool
+	OldState TCPState
+	NewState TCPState
+	Src      string
+	Dst      string
+	Duration time.Duration
+}
+
+// NetConn is the tracked state of a tcp socket.
+type NetConn struct {
+	Sock    string
+	Pid     PID
+	Src     string
+	Dst     string
+	State   TCPState
+	History []TCPState
+	// Start is zero for connections that were already open when pst
+	// started, their age is unknown.
+	Start    time.Time
+	Duration time.Duration
+}
+
+func (c NetConn) Closed() bool {
+	return c.State == TCPClose
+}
+
+// connKey identifies a connection by its endpoints, so connections read
+// from /proc can be matched with the tracer, which only knows the kernel
+// socket address. v4-mapped v6 addresses are folded into v4.
+func connKey(src, dst string) string {
+	return normalizeAddr(src) + " " + normalizeAddr(dst)
+}
+
+func normalizeAddr(addr string) string {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		host = ip.String()
+	}
+	return net.JoinHostPort(host, port)
+}
+
+// OpenConns returns the tcp connections pid currently holds, used to seed
+// the trace with connections opened before the tracer started.
+func OpenConns(pid PID) ([]NetConn, error) {
+	fds, err := Fds(pid)
+	if err != nil {
+		return nil, err
+	}
+	sockets, err := ReadSockets(pid)
+	if err != nil {
+		return nil, err
+	}
+
+	states := make(map[string]TCPState, len(tcpStateNames))
+	for st, name := range tcpStateNames {
+		states[name] = st
+	}
+
+	conns := make([]NetConn, 0, 4)
+	for _, fd := range fds {
+		kind, ino, ok := Inode(fd.Target)
+		if !ok || kind != "socket" {
+			continue
+		}
+		s, ok := sockets[ino]
+		if !ok || !strings.HasPrefix(s.Proto, "tcp") {
+			continue
+		}
+		st, ok := states[s.State]
+		if !ok || st == TCPListen || st == TCPClose {
+			continue
+		}
+		conns = append(conns, NetConn{
+			Pid:     pid,
+			Src:     s.Local,
+			Dst:     s.Remote,
+			State:   st,
+			History: []TCPState{st},
+		})
+	}
+	return conns, nil
+}
+
+func NewNetDataSource() (Datasource[NetData], error) {
+	// The tracer is not filtered: transitions outside process context
+	// can't be attributed to a pid in bpftrace.
+	const netTrace = `
+tracepoint:sock:inet_sock_set_state
+/args->protocol == 6/
+{
+	$sk = (uint64)args->skaddr;
+	if (args->newstate == 2 || args->newstate == 10) {
+		@sk_pid[$sk] = pid;
+	}
+	if (@sk_birth[$sk] == 0) {
+		@sk_birth[$sk] = nsecs;
+	}
+	$dur = nsecs - @sk_birth[$sk];
+
+	if (args->family == 2) {
+		printf("%lx %d %d %d %s %d %s %d %d\n", $sk, @sk_pid[$sk],
+			args->oldstate, args->newstate,
+			ntop(args->saddr), args->sport, ntop(args->daddr), args->dport, $dur);
+	} else {
+		printf("%lx %d %d %d [%s] %d [%s] %d %d\n", $sk, @sk_pid[$sk],
+			args->oldstate, args->newstate,
+			ntop(args->saddr_v6), args->sport, ntop(args->daddr_v6), args->dport, $dur);
+	}
+
+	if (args->newstate == 7) {
+		delete(@sk_birth[$sk]);
+		delete(@sk_pid[$sk]);
+	}
+}
+
+kretprobe:inet_csk_accept
+/retval/
+{
+	printf("%lx %d accept\n", retval, pid);
+}
+
+END
+{
+	clear(@sk_birth);
+	clear(@sk_pid);
+}
+`
+	return NewSource(netTrace, Filter{}, func(line string) (PID, NetData, error) {
+		s := strings.Split(strings.TrimSpace(line), " ")
+		if len(s) == 3 && s[2] == "accept" {
+			return PID(s[1]), NetData{Sock: s[0], Accept: true}, nil
+		}
+		if len(s) != 9 {
+			return PID(""), NetData{}, fmt.Errorf("unable to parse '%s'\n", line)
+		}
+
+		oldState, err := strconv.Atoi(s[2])
+		if err != nil {
+			return PID(""), NetData{}, fmt.Errorf("invalid state '%s'\n", line)
+		}
+		newState, err := strconv.Atoi(s[3])
+		if err != nil {
+			return PID(""), NetData{}, fmt.Errorf("invalid state '%s'\n", line)
+		}
+		dur, err := strconv.ParseInt(s[8], 10, 64)
+		if err != nil {
+			return PID(""), NetData{}, fmt.Errorf("invalid duration '%s'\n", line)
+		}
+
+		return PID(s[1]), NetData{
+			Sock:     s[0],
+			OldState: TCPState(oldState),
+			NewState: TCPState(newState),
+			Src:      s[4] + ":" + s[5],
+			Dst:      s[6] + ":" + s[7],
+			Duration: time.Duration(dur),
+		}, nil
+	})
+}
@@ -0,0 +1,62 @@
+package proc
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// ParseLimitValue parses a limit as entered by the user, a number or
+// "unlimited".
+func ParseLimitValue(s string) (uint64, error) {
+	s = strings.TrimSpace(s)
+	if s == "unlimited" {
+		return Unlimited, nil
+	}
+	n, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid limit '%s'", s)
+	}
+	return n, nil
+}
+
+// FormatLimitValue is the inverse of ParseLimitValue.
+func FormatLimitValue(v uint64) string {
+	if v == Unlimited {
+		return "unlimited"
+	}
+	return strconv.FormatUint(v, 10)
+}
+
+// ValidateLimit checks a new soft and hard limit for l. Raising the hard
+// limit needs CAP_SYS_RESOURCE, which pst is checked for.
+func ValidateLimit(l Limit, soft, hard uint64) error {
+	if soft > hard {
+		return fmt.Errorf("soft limit %s exceeds hard limit %s", FormatLimitValue(soft), FormatLimitValue(hard))
+	}
+	if _, ok := limitResources[l.Name]; !ok {
+		return fmt.Errorf("unknown limit '%s'", l.Name)
+	}
+	if hard > l.Hard && !canRaiseLimits() {
+		return fmt.Errorf("raising the hard limit above %s needs CAP_SYS_RESOURCE", FormatLimitValue(l.Hard))
+	}
+	return nil
+}
+
+// canRaiseLimits reports whether pst has CAP_SYS_RESOURCE.
+func canRaiseLimits() bool {
+	sec, err := GetSecurity(PID(strconv.Itoa(os.Getpid())))
+	if err != nil {
+		return false
+	}
+	return sec.Caps["CapEff"].HasName("sys_resource")
+}
+
+// SetLimit changes the limit l of pid with prlimit(2).
+func SetLimit(pid PID, l Limit, soft, hard uint64) error {
+	if err := ValidateLimit(l, soft, hard); err != nil {
+		return err
+	}
+	return prlimit(pid, limitResources[l.Name], soft, hard)
+}
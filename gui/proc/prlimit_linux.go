@@ -0,0 +1,28 @@
+package proc
+
+import "golang.org/x/sys/unix"
+
+// limitResources maps the names of /proc/<pid>/limits to resources.
+var limitResources = map[string]int{
+	"Max cpu time":          unix.RLIMIT_CPU,
+	"Max file size":         unix.RLIMIT_FSIZE,
+	"Max data size":         unix.RLIMIT_DATA,
+	"Max stack size":        unix.RLIMIT_STACK,
+	"Max core file size":    unix.RLIMIT_CORE,
+	"Max resident set":      unix.RLIMIT_RSS,
+	"Max processes":         unix.RLIMIT_NPROC,
+	"Max open files":        unix.RLIMIT_NOFILE,
+	"Max locked memory":     unix.RLIMIT_MEMLOCK,
+	"Max address space":     unix.RLIMIT_AS,
+	"Max file locks":        unix.RLIMIT_LOCKS,
+	"Max pending signals":   unix.RLIMIT_SIGPENDING,
+	"Max msgqueue size":     unix.RLIMIT_MSGQUEUE,
+	"Max nice priority":     unix.RLIMIT_NICE,
+	"Max realtime priority": unix.RLIMIT_RTPRIO,
+	"Max realtime timeout":  unix.RLIMIT_RTTIME,
+}
+
+func prlimit(pid PID, resource int, soft, hard uint64) error {
+	// RLIM_INFINITY is all ones, as Unlimited
+	return unix.Prlimit(pid.Int(), resource, &unix.Rlimit{Cur: soft, Max: hard}, nil)
+}
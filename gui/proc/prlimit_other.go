@@ -0,0 +1,11 @@
+//go:build !linux
+
+package proc
+
+import "errors"
+
+var limitResources = map[string]int{}
+
+func prlimit(pid PID, resource int, soft, hard uint64) error {
+	return errors.New("prlimit is only supported on linux")
+}
@@ -0,0 +1,179 @@
+package proc
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const probeLogSize = 100
+
+// Probe is a user defined bpftrace program loaded from the probe directory.
+//
+// Each line printed by the program is split on spaces into Fields, the
+// last field takes the rest of the line. If Key names one of the fields,
+// events are keyed by it as a PID and only shown for that process.
+type Probe struct {
+	Title       string   `json:"title"`
+	Program     string   `json:"program"`
+	ProgramFile string   `json:"programFile"`
+	Fields      []string `json:"fields"`
+	Key         string   `json:"key"`
+}
+
+type ProbeData struct {
+	Values []string
+}
+
+// ProbeDir is where probe definitions (*.json) are loaded from.
+func ProbeDir() string {
+	if dir := os.Getenv("PST_PROBE_DIR"); dir != "" {
+		return dir
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return path.Join(dir, "pst", "probes")
+}
+
+func ProbeFiles(dir string) ([]string, error) {
+	if dir == "" {
+		return nil, nil
+	}
+	files, err := filepath.Glob(path.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+func LoadProbe(file string) (Probe, error) {
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		return Probe{}, err
+	}
+
+	var p Probe
+	if err := json.Unmarshal(b, &p); err != nil {
+		return Probe{}, fmt.Errorf("%s: %v", file, err)
+	}
+
+	if p.ProgramFile != "" {
+		f := p.ProgramFile
+		if !path.IsAbs(f) {
+			f = path.Join(path.Dir(file), f)
+		}
+		b, err := ioutil.ReadFile(f)
+		if err != nil {
+			return Probe{}, err
+		}
+		p.Program = string(b)
+	}
+
+	if p.Program == "" {
+		return Probe{}, fmt.Errorf("%s: no program", file)
+	}
+	if len(p.Fields) == 0 {
+		return Probe{}, fmt.Errorf("%s: no fields", file)
+	}
+	if p.Key != "" && p.keyIndex() < 0 {
+		return Probe{}, fmt.Errorf("%s: key '%s' is not a field", file, p.Key)
+	}
+	if p.Title == "" {
+		p.Title = strings.TrimSuffix(path.Base(file), ".json")
+	}
+	return p, nil
+}
+
+func (p Probe) keyIndex() int {
+	for i, f := range p.Fields {
+		if f == p.Key {
+			return i
+		}
+	}
+	return -1
+}
+
+// ProbeSource runs a Probe and keeps its most recent events per PID.
+type ProbeSource struct {
+	Probe Probe
+	ds    Datasource[ProbeData]
+	lock  *sync.RWMutex
+	log   map[PID][]ProbeData
+}
+
+func NewProbeSource(probe Probe, filter Filter) (*ProbeSource, error) {
+	key := -1
+	if probe.Key != "" {
+		key = probe.keyIndex()
+	}
+
+	ds, err := NewSource(probe.Program, filter, func(line string) (PID, ProbeData, error) {
+		s := strings.SplitN(strings.TrimSpace(line), " ", len(probe.Fields))
+		if len(s) != len(probe.Fields) {
+			return PID(""), ProbeData{}, fmt.Errorf("unable to parse '%s'\n", line)
+		}
+		if key < 0 {
+			return PID(""), ProbeData{Values: s}, nil
+		}
+		if _, err := strconv.Atoi(s[key]); err != nil {
+			return PID(""), ProbeData{}, fmt.Errorf("invalid pid '%s'\n", line)
+		}
+		return PID(s[key]), ProbeData{Values: s}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ps := &ProbeSource{
+		Probe: probe,
+		ds:    ds,
+		lock:  &sync.RWMutex{},
+		log:   make(map[PID][]ProbeData),
+	}
+
+	pidCh, dataCh, err := ds.GetStream()
+	if err != nil {
+		return nil, err
+	}
+
+	go func() {
+		for pid := range pidCh {
+			e := <-dataCh
+
+			ps.lock.Lock()
+			d := append(ps.log[pid], e)
+			if len(d) > probeLogSize {
+				d = d[len(d)-probeLogSize:]
+			}
+			ps.log[pid] = d
+			ps.lock.Unlock()
+		}
+	}()
+
+	return ps, nil
+}
+
+func (ps *ProbeSource) SetFilter(filter Filter) error {
+	return ps.ds.SetFilter(filter)
+}
+
+// Events returns the events recorded for pid, or every event for probes
+// that are not keyed by PID.
+func (ps *ProbeSource) Events(pid PID) []ProbeData {
+	if ps.Probe.Key == "" {
+		pid = PID("")
+	}
+	ps.lock.RLock()
+	defer ps.lock.RUnlock()
+	return ps.log[pid]
+}
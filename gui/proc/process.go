@@ -9,6 +9,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 )
 
 type Process struct {
@@ -21,69 +22,228 @@ type Process struct {
 type ProcDataSource interface {
 	GetProcesses(filters ...string) map[PID]Process
 	GetProcess(pid PID) *Process
+	GetFdTrend(pid PID) FdTrend
+	// GetFdCount returns the last sampled fd count of pid.
+	GetFdCount(pid PID) (int, bool)
+	// GetCPU and GetThreadCPU return the current cpu usage, 100 being
+	// one cpu fully used.
+	GetCPU(pid PID) (float64, bool)
+	GetThreadCPU(tid PID) (float64, bool)
+	GetHistory(pid PID) []HistorySample
+	// GetStuckTasks returns the tasks in D state, longest stuck first.
+	GetStuckTasks() []StuckTask
+	// NearLimit reports whether pid uses most of one of its resource
+	// limits, as last sampled.
+	NearLimit(pid PID) bool
 
 	// ebpf based
+	// TraceError returns why the tracers are not running, nil if they are.
+	TraceError() error
+	// SetTraceFilter restricts the open and chdir tracers to filter. The
+	// exec tracer stays system wide since it feeds the process list.
+	SetTraceFilter(filter Filter) error
+	// AddProbe starts a user defined probe, following SetTraceFilter.
+	AddProbe(probe Probe) (*ProbeSource, error)
 	GetExecTrace(pid PID) []ExecData
 	GetOpenTrace(pid PID) []OpenData
 	GetChdirTrace(pid PID) []ChdirData
+	GetNetTrace(pid PID) []NetConn
+	// GetSyscallStats sums the syscall stats of pids.
+	GetSyscallStats(pids ...PID) []SyscallStat
 }
 
 type procDataSource struct {
 	execDs  Datasource[ExecData]
 	openDs  Datasource[OpenData]
 	chdirDs Datasource[ChdirData]
+	statDs  Datasource[SyscallStatData]
+	netDs   Datasource[NetData]
 
+	traceLock     *sync.RWMutex
+	filter        Filter
+	probes        []*ProbeSource
 	openLog       map[PID][]OpenData
+	chdirLog      map[PID][]ChdirData
+	statLog       map[PID]map[int]*SyscallStat
+	netConns      map[string]*NetConn
+	netLog        map[PID][]*NetConn
+	netSeeds      map[string]*NetConn
+	netSeeded     map[PID]bool
 	procCacheLock *sync.RWMutex
 	procCache     map[PID]ExecData
+
+	fdSampler  *FdSampler
+	cpuSampler *CPUSampler
+	history    *History
+	dstate     *DStateSampler
+	limits     *LimitSampler
 }
 
 func NewProcDataSource() (*procDataSource, error) {
-	execDs, err := NewExecDataSource()
-	if err != nil {
-		return &procDataSource{}, err
+	// tracers that fail to start (no bpftrace, no privileges) stay empty,
+	// pst keeps working from /proc and reports it with TraceError.
+	execDs, _ := NewExecDataSource()
+	openDs, _ := NewOpenDataSource(Filter{})
+	chdirDs, _ := NewChdirDataSource(Filter{})
+	statDs, _ := NewSyscallStatDataSource(Filter{})
+	netDs, _ := NewNetDataSource()
+
+	pds := procDataSource{
+		execDs:        execDs,
+		openDs:        openDs,
+		openLog:       make(map[PID][]OpenData),
+		chdirDs:       chdirDs,
+		chdirLog:      make(map[PID][]ChdirData),
+		statDs:        statDs,
+		statLog:       make(map[PID]map[int]*SyscallStat),
+		netDs:         netDs,
+		netConns:      make(map[string]*NetConn),
+		netLog:        make(map[PID][]*NetConn),
+		netSeeds:      make(map[string]*NetConn),
+		netSeeded:     make(map[PID]bool),
+		traceLock:     &sync.RWMutex{},
+		procCache:     make(map[PID]ExecData),
+		procCacheLock: &sync.RWMutex{},
+		fdSampler:     NewFdSampler(),
+		cpuSampler:    NewCPUSampler(),
+		dstate:        NewDStateSampler(),
 	}
-	openDs, err := NewOpenDataSource()
+	pds.history = NewHistory(pds.cpuSampler, pds.fdSampler)
+	pds.limits = NewLimitSampler(pds.fdSampler)
+
+	chdirDsPID, chdirDsData, err := chdirDs.GetStream()
 	if err != nil {
-		return &procDataSource{}, err
+		os.Exit(1)
 	}
-	chdirDs, err := NewChdirDataSource()
+	openDsPID, openDsData, err := openDs.GetStream()
 	if err != nil {
-		return &procDataSource{}, err
+		os.Exit(1)
 	}
 
-	openDsPID, openDsData, err := openDs.GetStream()
+	// chdir and open are consumed by one goroutine, with pending chdirs
+	// drained first, so that an open is resolved against the cwd the
+	// process had at the time of the syscall rather than at processing time.
+	go func() {
+		for {
+			select {
+			case pid, ok := <-chdirDsPID:
+				if !ok {
+					return
+				}
+				pds.addChdirEvent(pid, <-chdirDsData)
+				continue
+			default:
+			}
+
+			select {
+			case pid, ok := <-chdirDsPID:
+				if !ok {
+					return
+				}
+				pds.addChdirEvent(pid, <-chdirDsData)
+			case pid, ok := <-openDsPID:
+				if !ok {
+					return
+				}
+				pds.addOpenEvent(pid, <-openDsData)
+			}
+		}
+	}()
+
+	statDsPID, statDsData, err := statDs.GetStream()
 	if err != nil {
 		os.Exit(1)
 	}
 
-	opens := make(map[PID][]OpenData)
 	go func() {
-		for pid := range openDsPID {
-			e := <-openDsData
+		for pid := range statDsPID {
+			e := <-statDsData
 
-			d, ok := opens[pid]
+			pds.traceLock.Lock()
+			stats, ok := pds.statLog[pid]
 			if !ok {
-				d = make([]OpenData, 0, 4)
+				stats = make(map[int]*SyscallStat)
+				pds.statLog[pid] = stats
 			}
-			opens[pid] = append(d, e)
+			stat, ok := stats[e.Nr]
+			if !ok {
+				stat = &SyscallStat{Nr: e.Nr}
+				stats[e.Nr] = stat
+			}
+			stat.add(e)
+			pds.traceLock.Unlock()
 		}
 	}()
 
-	pds := procDataSource{
-		execDs:        execDs,
-		openDs:        openDs,
-		openLog:       opens,
-		chdirDs:       chdirDs,
-		procCache:     make(map[PID]ExecData),
-		procCacheLock: &sync.RWMutex{},
+	netDsPID, netDsData, err := netDs.GetStream()
+	if err != nil {
+		os.Exit(1)
 	}
 
+	go func() {
+		for pid := range netDsPID {
+			e := <-netDsData
+			pds.addNetEvent(pid, e)
+		}
+	}()
+
 	pds.bootstrapProcCache()
 
+	go func() {
+		for range time.Tick(pruneInterval) {
+			pds.pruneTraces()
+		}
+	}()
+
 	return &pds, nil
 }
 
+// pruneInterval is how often per-process trace state of exited processes
+// is dropped.
+const pruneInterval = 5 * time.Second
+
+// pruneTraces forgets the chdirs, syscall stats and connections of
+// processes that have exited.
+func (pds *procDataSource) pruneTraces() {
+	live := make(map[PID]bool)
+	for _, pid := range Pids() {
+		live[pid] = true
+	}
+
+	pds.traceLock.Lock()
+	defer pds.traceLock.Unlock()
+	for pid := range pds.chdirLog {
+		if !live[pid] {
+			delete(pds.chdirLog, pid)
+		}
+	}
+	for pid := range pds.statLog {
+		if !live[pid] {
+			delete(pds.statLog, pid)
+		}
+	}
+	for pid := range pds.netLog {
+		if !live[pid] {
+			delete(pds.netLog, pid)
+		}
+	}
+	for pid := range pds.netSeeded {
+		if !live[pid] {
+			delete(pds.netSeeded, pid)
+		}
+	}
+	for sock, c := range pds.netConns {
+		if c.Pid != "" && !live[c.Pid] {
+			delete(pds.netConns, sock)
+		}
+	}
+	for key, c := range pds.netSeeds {
+		if !live[c.Pid] {
+			delete(pds.netSeeds, key)
+		}
+	}
+}
+
 func (pds *procDataSource) GetProcess(pid PID) *Process {
 	pds.procCacheLock.RLock()
 	p, ok := pds.procCache[pid]
@@ -103,17 +263,40 @@ func (pds *procDataSource) GetProcess(pid PID) *Process {
 	}
 }
 
-func (pds *procDataSource) bootstrapProcCache() {
-	files, err := filepath.Glob("/proc/*")
+func (pds *procDataSource) GetFdTrend(pid PID) FdTrend {
+	return pds.fdSampler.Trend(pid)
+}
+
+func (pds *procDataSource) GetFdCount(pid PID) (int, bool) {
+	key, err := GetProcKey(pid)
 	if err != nil {
-		panic("glob panicked")
+		return 0, false
 	}
-	for _, f := range files {
-		candidate := path.Base(f)
-		if _, err := strconv.Atoi(candidate); err != nil {
-			continue
-		}
-		pid := PID(candidate)
+	return pds.fdSampler.Latest(key)
+}
+
+func (pds *procDataSource) GetCPU(pid PID) (float64, bool) {
+	return pds.cpuSampler.Process(pid)
+}
+
+func (pds *procDataSource) GetThreadCPU(tid PID) (float64, bool) {
+	return pds.cpuSampler.Thread(tid)
+}
+
+func (pds *procDataSource) GetHistory(pid PID) []HistorySample {
+	return pds.history.Samples(pid)
+}
+
+func (pds *procDataSource) GetStuckTasks() []StuckTask {
+	return pds.dstate.Stuck()
+}
+
+func (pds *procDataSource) NearLimit(pid PID) bool {
+	return pds.limits.Near(pid)
+}
+
+func (pds *procDataSource) bootstrapProcCache() {
+	for _, pid := range Pids() {
 		pds.procCacheLock.Lock()
 		pds.procCache[pid] = ExecData{
 			Command: GetCommand(pid),
@@ -143,7 +326,7 @@ func (pds *procDataSource) GetProcesses(filters ...string) map[PID]Process {
 
 	results := make(map[PID]Process)
 	for pid, p := range pds.procCache {
-		if !strings.Contains(p.Command, filters[0]) {
+		if !MatchProcess(pid, p.Command, filters[0]) {
 			continue
 		}
 		results[pid] = Process{
@@ -184,14 +367,260 @@ func GetChildren(pid PID) []PID {
 	return pids
 }
 
+// GetDescendants returns pid and every process below it.
+func GetDescendants(pid PID) []PID {
+	pids := []PID{pid}
+	for i := 0; i < len(pids); i++ {
+		pids = append(pids, GetChildren(pids[i])...)
+	}
+	return pids
+}
+
+func (pds *procDataSource) TraceError() error {
+	for _, err := range []error{
+		pds.execDs.Err(), pds.openDs.Err(), pds.chdirDs.Err(),
+		pds.statDs.Err(), pds.netDs.Err(),
+	} {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (pds *procDataSource) SetTraceFilter(filter Filter) error {
+	pds.traceLock.Lock()
+	pds.filter = filter
+	probes := pds.probes
+	pds.traceLock.Unlock()
+
+	if err := pds.openDs.SetFilter(filter); err != nil {
+		return err
+	}
+	if err := pds.chdirDs.SetFilter(filter); err != nil {
+		return err
+	}
+	if err := pds.statDs.SetFilter(filter); err != nil {
+		return err
+	}
+	for _, p := range probes {
+		if err := p.SetFilter(filter); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (pds *procDataSource) AddProbe(probe Probe) (*ProbeSource, error) {
+	pds.traceLock.Lock()
+	defer pds.traceLock.Unlock()
+
+	ps, err := NewProbeSource(probe, pds.filter)
+	if err != nil {
+		return nil, err
+	}
+	pds.probes = append(pds.probes, ps)
+	return ps, nil
+}
+
 func (pds *procDataSource) GetExecTrace(pid PID) []ExecData {
 	panic("unimplemented")
 }
 func (pds *procDataSource) GetOpenTrace(pid PID) []OpenData {
+	pds.traceLock.RLock()
+	defer pds.traceLock.RUnlock()
 	return pds.openLog[pid]
 }
 func (pds *procDataSource) GetChdirTrace(pid PID) []ChdirData {
-	panic("unimplemented")
+	pds.traceLock.RLock()
+	defer pds.traceLock.RUnlock()
+	return pds.chdirLog[pid]
+}
+
+func (pds *procDataSource) addNetEvent(pid PID, e NetData) {
+	pds.traceLock.Lock()
+	defer pds.traceLock.Unlock()
+
+	c, ok := pds.netConns[e.Sock]
+	if !ok && !e.Accept {
+		// first event of a connection seeded from /proc
+		key := connKey(e.Src, e.Dst)
+		if c, ok = pds.netSeeds[key]; ok {
+			delete(pds.netSeeds, key)
+			c.Sock = e.Sock
+			pds.netConns[e.Sock] = c
+		}
+	}
+	if !ok {
+		c = &NetConn{
+			Sock:  e.Sock,
+			Start: time.Now().Add(-e.Duration),
+		}
+		pds.netConns[e.Sock] = c
+	}
+
+	if pid != "0" && c.Pid == "" {
+		c.Pid = pid
+		conns := append(pds.netLog[pid], c)
+		if len(conns) > netLogSize {
+			conns = conns[len(conns)-netLogSize:]
+		}
+		pds.netLog[pid] = conns
+	}
+
+	if e.Accept {
+		return
+	}
+	c.Src, c.Dst = e.Src, e.Dst
+	c.State = e.NewState
+	c.History = append(c.History, e.NewState)
+	c.Duration = e.Duration
+	if e.NewState == TCPClose {
+		delete(pds.netConns, e.Sock)
+	}
+}
+
+// seedNetConns adds the connections pid held before it was first looked at,
+// which the tracer never saw being opened.
+func (pds *procDataSource) seedNetConns(pid PID) {
+	pds.traceLock.RLock()
+	seeded := pds.netSeeded[pid]
+	pds.traceLock.RUnlock()
+	if seeded {
+		return
+	}
+
+	conns, err := OpenConns(pid)
+
+	pds.traceLock.Lock()
+	defer pds.traceLock.Unlock()
+	pds.netSeeded[pid] = true
+	if err != nil {
+		return
+	}
+
+	known := make(map[string]bool)
+	for _, c := range pds.netLog[pid] {
+		if !c.Closed() {
+			known[connKey(c.Src, c.Dst)] = true
+		}
+	}
+	for i := range conns {
+		key := connKey(conns[i].Src, conns[i].Dst)
+		if known[key] {
+			continue
+		}
+		c := &conns[i]
+		pds.netSeeds[key] = c
+		pds.netLog[pid] = append(pds.netLog[pid], c)
+	}
+}
+
+func (pds *procDataSource) GetNetTrace(pid PID) []NetConn {
+	pds.seedNetConns(pid)
+
+	pds.traceLock.RLock()
+	defer pds.traceLock.RUnlock()
+
+	conns := make([]NetConn, 0, len(pds.netLog[pid]))
+	for _, c := range pds.netLog[pid] {
+		conn := *c
+		conn.History = append([]TCPState(nil), c.History...)
+		conns = append(conns, conn)
+	}
+	return conns
+}
+
+func (pds *procDataSource) GetSyscallStats(pids ...PID) []SyscallStat {
+	pds.traceLock.RLock()
+	defer pds.traceLock.RUnlock()
+
+	sum := make(map[int]*SyscallStat)
+	for _, pid := range pids {
+		for nr, stat := range pds.statLog[pid] {
+			s, ok := sum[nr]
+			if !ok {
+				s = &SyscallStat{Nr: nr, Hist: make(map[time.Duration]uint64)}
+				sum[nr] = s
+			}
+			s.Count += stat.Count
+			s.Time += stat.Time
+			for b, n := range stat.Hist {
+				s.Hist[b] += n
+			}
+		}
+	}
+
+	stats := make([]SyscallStat, 0, len(sum))
+	for _, s := range sum {
+		stats = append(stats, *s)
+	}
+	return stats
+}
+
+// addChdirEvent records a chdir of pid, resolving a relative directory
+// against the cwd pid had at the time.
+func (pds *procDataSource) addChdirEvent(pid PID, e ChdirData) {
+	if e.Cwd != "" && !path.IsAbs(e.Cwd) {
+		e.Cwd = path.Join(pds.cwd(pid, e.Time), e.Cwd)
+	}
+
+	pds.traceLock.Lock()
+	pds.chdirLog[pid] = append(pds.chdirLog[pid], e)
+	pds.traceLock.Unlock()
+}
+
+func (pds *procDataSource) addOpenEvent(pid PID, e OpenData) {
+	e.Filepath = pds.resolvePath(pid, e.Time, e.Dirfd, e.Filepath)
+
+	pds.traceLock.Lock()
+	d, ok := pds.openLog[pid]
+	if !ok {
+		d = make([]OpenData, 0, 4)
+	}
+	pds.openLog[pid] = append(d, e)
+	pds.traceLock.Unlock()
+}
+
+// cwd returns the working directory pid had at time t, from the last traced
+// chdir before t, falling back to /proc when no usable chdir was traced.
+func (pds *procDataSource) cwd(pid PID, t uint64) string {
+	pds.traceLock.RLock()
+	trace := pds.chdirLog[pid]
+	pds.traceLock.RUnlock()
+	for i := len(trace) - 1; i >= 0; i-- {
+		if trace[i].Time > t {
+			continue
+		}
+		if trace[i].Cwd != "" {
+			return trace[i].Cwd
+		}
+		break
+	}
+
+	d, err := pds.chdirDs.Get(pid)
+	if err != nil {
+		return ""
+	}
+	return d.Cwd
+}
+
+// resolvePath turns a path passed to open/openat into an absolute path.
+func (pds *procDataSource) resolvePath(pid PID, t uint64, dirfd int, p string) string {
+	if path.IsAbs(p) {
+		return p
+	}
+
+	var dir string
+	if dirfd == AtFdCwd {
+		dir = pds.cwd(pid, t)
+	} else {
+		dir, _ = os.Readlink(path.Join("/proc", pid.String(), "fd", strconv.Itoa(dirfd)))
+	}
+	if dir == "" {
+		return p
+	}
+	return path.Join(dir, p)
 }
 
 func Kill(pid PID) error {
@@ -0,0 +1,187 @@
+package proc
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ProfileHz is the sampling frequency of the profiler, off the round
+// numbers so it doesn't run in lockstep with timers.
+const ProfileHz = 99
+
+// ProfileStack is a sampled stack. Frames are innermost first, as
+// printed by bpftrace, without offsets.
+type ProfileStack struct {
+	Pid    PID
+	Comm   string
+	User   []string
+	Kernel []string
+	Count  uint64
+}
+
+// Folded renders the stack as a line of Brendan Gregg's folded format,
+// outermost frame first and kernel frames suffixed with _[k].
+func (s ProfileStack) Folded() string {
+	frames := make([]string, 0, 1+len(s.User)+len(s.Kernel))
+	frames = append(frames, s.Comm)
+	for i := len(s.User) - 1; i >= 0; i-- {
+		frames = append(frames, s.User[i])
+	}
+	for i := len(s.Kernel) - 1; i >= 0; i-- {
+		frames = append(frames, s.Kernel[i]+"_[k]")
+	}
+	for i, f := range frames {
+		// ';' separates frames and ' ' the count
+		frames[i] = strings.NewReplacer(";", ":", " ", "_").Replace(f)
+	}
+	return fmt.Sprintf("%s %d", strings.Join(frames, ";"), s.Count)
+}
+
+// Profile is the result of a profiling run, stacks sorted by count.
+type Profile struct {
+	Pids     []PID
+	Duration time.Duration
+	Samples  uint64
+	Stacks   []ProfileStack
+}
+
+// WriteFolded writes the profile in the folded format flamegraph.pl and
+// compatible tools read.
+func (p Profile) WriteFolded(w io.Writer) error {
+	for _, s := range p.Stacks {
+		if _, err := fmt.Fprintln(w, s.Folded()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SaveFolded writes the profile in folded format to file.
+func (p Profile) SaveFolded(file string) error {
+	f, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	if err := p.WriteFolded(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+var (
+	profileKeyRe = regexp.MustCompile(`^, (\d+), (.*)\]: (\d+)$`)
+	// "func+12" or "func+12 (/usr/lib/libc.so.6)"
+	frameOffsetRe = regexp.MustCompile(`\+\d+( \(.*\))?$`)
+)
+
+// parseProfile parses the printed @stacks[kstack, ustack, pid, comm] map,
+// whose keys span several lines:
+//
+//	@stacks[
+//	        kfunc+12
+//	,
+//	        ufunc+3
+//	, 1234, comm]: 5
+func parseProfile(r io.Reader) ([]ProfileStack, error) {
+	merged := make(map[string]*ProfileStack)
+	var sections [][]string
+	inKey := false
+
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		line := sc.Text()
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == "@stacks[":
+			inKey = true
+			sections = [][]string{nil}
+		case !inKey:
+		case trimmed == ",":
+			sections = append(sections, nil)
+		case profileKeyRe.MatchString(line):
+			m := profileKeyRe.FindStringSubmatch(line)
+			count, _ := strconv.ParseUint(m[3], 10, 64)
+			s := ProfileStack{Pid: PID(m[1]), Comm: m[2], Count: count}
+			s.Kernel = sections[0]
+			if len(sections) > 1 {
+				s.User = sections[1]
+			}
+			key := s.Folded()
+			key = key[:strings.LastIndexByte(key, ' ')]
+			if prev, ok := merged[key]; ok {
+				prev.Count += s.Count
+			} else {
+				merged[key] = &s
+			}
+			inKey = false
+		case trimmed != "":
+			frame := frameOffsetRe.ReplaceAllString(trimmed, "")
+			sections[len(sections)-1] = append(sections[len(sections)-1], frame)
+		}
+	}
+
+	stacks := make([]ProfileStack, 0, len(merged))
+	for _, s := range merged {
+		stacks = append(stacks, *s)
+	}
+	sort.Slice(stacks, func(i, j int) bool { return stacks[i].Count > stacks[j].Count })
+	return stacks, sc.Err()
+}
+
+// RunProfile samples the user and kernel stacks of pids at ProfileHz for
+// duration, blocking until done.
+func RunProfile(pids []PID, duration time.Duration) (Profile, error) {
+	const profileTrace = `
+profile:hz:%d
+/*filter*/
+{
+	@stacks[kstack, ustack, pid, comm] = count();
+}
+
+interval:s:%d
+{
+	print(@stacks);
+	clear(@stacks);
+	exit();
+}
+`
+	seconds := int(duration.Round(time.Second) / time.Second)
+	if seconds < 1 {
+		seconds = 1
+	}
+	program := applyFilter(fmt.Sprintf(profileTrace, ProfileHz, seconds), Filter{Pids: pids})
+
+	stderr := bytes.Buffer{}
+	cmd := exec.Command("bpftrace", "-e", program)
+	cmd.Stderr = &stderr
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		return Profile{}, err
+	}
+	if err := cmd.Start(); err != nil {
+		return Profile{}, err
+	}
+	stacks, err := parseProfile(out)
+	if werr := cmd.Wait(); werr != nil {
+		return Profile{}, fmt.Errorf("bpftrace: %s %s", werr, strings.TrimSpace(stderr.String()))
+	}
+	if err != nil {
+		return Profile{}, err
+	}
+
+	p := Profile{Pids: pids, Duration: time.Duration(seconds) * time.Second, Stacks: stacks}
+	for _, s := range stacks {
+		p.Samples += s.Count
+	}
+	return p, nil
+}
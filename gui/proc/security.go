@@ -0,0 +1,162 @@
+package proc
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// capNames are the capabilities by bit number, see capabilities(7).
+var capNames = []string{
+	"cap_chown",
+	"cap_dac_override",
+	"cap_dac_read_search",
+	"cap_fowner",
+	"cap_fsetid",
+	"cap_kill",
+	"cap_setgid",
+	"cap_setuid",
+	"cap_setpcap",
+	"cap_linux_immutable",
+	"cap_net_bind_service",
+	"cap_net_broadcast",
+	"cap_net_admin",
+	"cap_net_raw",
+	"cap_ipc_lock",
+	"cap_ipc_owner",
+	"cap_sys_module",
+	"cap_sys_rawio",
+	"cap_sys_chroot",
+	"cap_sys_ptrace",
+	"cap_sys_pacct",
+	"cap_sys_admin",
+	"cap_sys_boot",
+	"cap_sys_nice",
+	"cap_sys_resource",
+	"cap_sys_time",
+	"cap_sys_tty_config",
+	"cap_mknod",
+	"cap_lease",
+	"cap_audit_write",
+	"cap_audit_control",
+	"cap_setfcap",
+	"cap_mac_override",
+	"cap_mac_admin",
+	"cap_syslog",
+	"cap_wake_alarm",
+	"cap_block_suspend",
+	"cap_audit_read",
+	"cap_perfmon",
+	"cap_bpf",
+	"cap_checkpoint_restore",
+}
+
+// CapSet is a capability set from /proc/<pid>/status.
+type CapSet uint64
+
+// ParseCapSet parses a hex mask such as "000001ffffffffff".
+func ParseCapSet(s string) (CapSet, error) {
+	n, err := strconv.ParseUint(s, 16, 64)
+	if err != nil {
+		return 0, fmt.Errorf("unable to parse capabilities '%s'", s)
+	}
+	return CapSet(n), nil
+}
+
+func (c CapSet) Has(bit int) bool {
+	return c&(1<<uint(bit)) != 0
+}
+
+// Full reports whether c holds every capability pst knows of.
+func (c CapSet) Full() bool {
+	for bit := range capNames {
+		if !c.Has(bit) {
+			return false
+		}
+	}
+	return true
+}
+
+// Names returns the capabilities in c, unknown bits as "cap_<bit>".
+func (c CapSet) Names() []string {
+	var names []string
+	for bit := 0; bit < 64; bit++ {
+		if !c.Has(bit) {
+			continue
+		}
+		if bit < len(capNames) {
+			names = append(names, capNames[bit])
+		} else {
+			names = append(names, fmt.Sprintf("cap_%d", bit))
+		}
+	}
+	return names
+}
+
+// HasName reports whether c holds the capability name, with or without
+// the cap_ prefix.
+func (c CapSet) HasName(name string) bool {
+	name = strings.ToLower(name)
+	if !strings.HasPrefix(name, "cap_") {
+		name = "cap_" + name
+	}
+	for _, n := range c.Names() {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// CapSetNames are the capability sets of /proc/<pid>/status in the
+// order they are shown.
+var CapSetNames = []string{"CapInh", "CapPrm", "CapEff", "CapBnd", "CapAmb"}
+
+var seccompModes = map[string]string{
+	"0": "disabled",
+	"1": "strict",
+	"2": "filter",
+}
+
+// Security is the security context of a process.
+type Security struct {
+	Caps map[string]CapSet
+	// Seccomp is "disabled", "strict" or "filter".
+	Seccomp    string
+	NoNewPrivs bool
+	// Label is the SELinux context or AppArmor profile, "" without LSM.
+	Label string
+}
+
+func GetSecurity(pid PID) (Security, error) {
+	status, err := GetStatus(pid)
+	if err != nil {
+		return Security{}, err
+	}
+
+	sec := Security{Caps: make(map[string]CapSet, len(CapSetNames))}
+	for _, name := range CapSetNames {
+		v, ok := status[name]
+		if !ok {
+			continue
+		}
+		caps, err := ParseCapSet(v)
+		if err != nil {
+			return Security{}, err
+		}
+		sec.Caps[name] = caps
+	}
+	sec.Seccomp = seccompModes[status["Seccomp"]]
+	sec.NoNewPrivs = status["NoNewPrivs"] == "1"
+	sec.Label = GetLabel(pid)
+	return sec, nil
+}
+
+// GetLabel returns the LSM label of pid from /proc/<pid>/attr/current.
+func GetLabel(pid PID) string {
+	label, err := readProcPath(pid, "attr/current")
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(strings.TrimRight(label, "\x00"))
+}
@@ -0,0 +1,270 @@
+package proc
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unsafe"
+)
+
+var nativeEndian binary.ByteOrder = func() binary.ByteOrder {
+	x := uint16(1)
+	if *(*byte)(unsafe.Pointer(&x)) == 1 {
+		return binary.LittleEndian
+	}
+	return binary.BigEndian
+}()
+
+// Socket is an entry of /proc/<pid>/net/{tcp,tcp6,udp,udp6,unix}.
+type Socket struct {
+	Inode  uint64
+	Proto  string
+	Local  string
+	Remote string
+	State  string
+	// Peer is the inode of the other end of a connected unix socket.
+	Peer uint64
+}
+
+// FdSocket is a socket held open by a process.
+type FdSocket struct {
+	Fd
+	Socket
+	// Peers are the fds holding the other end of a unix socket.
+	Peers []Fd
+}
+
+// ReadSockets parses the socket tables of the network namespace of pid.
+func ReadSockets(pid PID) (map[uint64]Socket, error) {
+	sockets := make(map[uint64]Socket)
+	for _, proto := range []string{"tcp", "tcp6", "udp", "udp6"} {
+		if err := readInetSockets(pid, proto, sockets); err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+	}
+	if err := readUnixSockets(pid, sockets); err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	return sockets, nil
+}
+
+func readInetSockets(pid PID, proto string, sockets map[uint64]Socket) error {
+	f, err := os.Open(path.Join("/proc", pid.String(), "net", proto))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	sc := bufio.NewScanner(f)
+	sc.Scan() // header
+	for sc.Scan() {
+		fields := strings.Fields(sc.Text())
+		if len(fields) < 10 {
+			continue
+		}
+		ino, err := strconv.ParseUint(fields[9], 10, 64)
+		if err != nil {
+			continue
+		}
+		local, err := parseInetAddr(fields[1])
+		if err != nil {
+			continue
+		}
+		remote, err := parseInetAddr(fields[2])
+		if err != nil {
+			continue
+		}
+		st, err := strconv.ParseUint(fields[3], 16, 8)
+		if err != nil {
+			continue
+		}
+
+		state := TCPState(st).String()
+		if strings.HasPrefix(proto, "udp") {
+			state = "UNCONN"
+			if TCPState(st) == TCPEstablished {
+				state = "ESTAB"
+			}
+		}
+
+		sockets[ino] = Socket{
+			Inode:  ino,
+			Proto:  proto,
+			Local:  local,
+			Remote: remote,
+			State:  state,
+		}
+	}
+	return sc.Err()
+}
+
+// parseInetAddr parses "0100007F:1F90" style addresses. The address is
+// made of 32 bit words in host byte order, the port is big endian.
+func parseInetAddr(s string) (string, error) {
+	i := strings.Index(s, ":")
+	if i < 0 {
+		return "", fmt.Errorf("invalid address '%s'", s)
+	}
+	b, err := hex.DecodeString(s[:i])
+	if err != nil || (len(b) != 4 && len(b) != 16) {
+		return "", fmt.Errorf("invalid address '%s'", s)
+	}
+	port, err := strconv.ParseUint(s[i+1:], 16, 16)
+	if err != nil {
+		return "", fmt.Errorf("invalid port '%s'", s)
+	}
+
+	ip := make(net.IP, len(b))
+	for w := 0; w < len(b); w += 4 {
+		word := nativeEndian.Uint32(b[w : w+4])
+		bigEndian(ip[w:w+4], word)
+	}
+	return net.JoinHostPort(ip.String(), strconv.FormatUint(port, 10)), nil
+}
+
+func bigEndian(b []byte, v uint32) {
+	b[0], b[1], b[2], b[3] = byte(v>>24), byte(v>>16), byte(v>>8), byte(v)
+}
+
+var unixTypes = map[string]string{
+	"0001": "stream",
+	"0002": "dgram",
+	"0005": "seqpacket",
+}
+
+var unixStates = map[string]string{
+	"01": "UNCONNECTED",
+	"02": "CONNECTING",
+	"03": "CONNECTED",
+	"04": "DISCONNECTING",
+}
+
+// unixAcceptCon is __SO_ACCEPTCON, set on listening unix sockets.
+const unixAcceptCon = 0x10000
+
+func readUnixSockets(pid PID, sockets map[uint64]Socket) error {
+	f, err := os.Open(path.Join("/proc", pid.String(), "net", "unix"))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	peers := map[uint64]uint64{}
+	if inOwnNetns(pid) {
+		peers = unixPeers()
+	}
+
+	sc := bufio.NewScanner(f)
+	sc.Scan() // header
+	for sc.Scan() {
+		// Num RefCount Protocol Flags Type St Inode [Path]
+		fields := strings.Fields(sc.Text())
+		if len(fields) < 7 {
+			continue
+		}
+		ino, err := strconv.ParseUint(fields[6], 10, 64)
+		if err != nil {
+			continue
+		}
+
+		state := unixStates[fields[5]]
+		if flags, err := strconv.ParseUint(fields[3], 16, 32); err == nil && flags&unixAcceptCon != 0 {
+			state = "LISTEN"
+		}
+		name := ""
+		if len(fields) > 7 {
+			name = fields[7]
+		}
+
+		sockets[ino] = Socket{
+			Inode: ino,
+			Proto: "unix/" + unixTypes[fields[4]],
+			Local: name,
+			State: state,
+			Peer:  peers[ino],
+		}
+	}
+	return sc.Err()
+}
+
+// inOwnNetns reports whether pid shares pst's network namespace, the only
+// one sock_diag dumps.
+func inOwnNetns(pid PID) bool {
+	ns, err := os.Readlink(path.Join("/proc", pid.String(), "ns", "net"))
+	if err != nil {
+		return false
+	}
+	self, err := os.Readlink("/proc/self/ns/net")
+	return err == nil && ns == self
+}
+
+// unixPeersTTL bounds how often the unix socket peers are dumped.
+const unixPeersTTL = 2 * time.Second
+
+var unixPeerCache = struct {
+	sync.Mutex
+	at    time.Time
+	peers map[uint64]uint64
+}{}
+
+// unixPeers returns UnixPeers, cached for unixPeersTTL.
+func unixPeers() map[uint64]uint64 {
+	unixPeerCache.Lock()
+	defer unixPeerCache.Unlock()
+
+	if unixPeerCache.peers != nil && time.Since(unixPeerCache.at) < unixPeersTTL {
+		return unixPeerCache.peers
+	}
+
+	peers, err := UnixPeers()
+	if err != nil {
+		peers = map[uint64]uint64{}
+	}
+	unixPeerCache.peers = peers
+	unixPeerCache.at = time.Now()
+	return peers
+}
+
+// ProcessSockets resolves the socket fds of pid, including the processes
+// on the other end of its unix sockets.
+func ProcessSockets(pid PID) ([]FdSocket, error) {
+	fds, err := Fds(pid)
+	if err != nil {
+		return nil, err
+	}
+	sockets, err := ReadSockets(pid)
+	if err != nil {
+		return nil, err
+	}
+
+	var index map[string][]Fd
+	results := make([]FdSocket, 0, len(fds))
+	for _, fd := range fds {
+		kind, ino, ok := Inode(fd.Target)
+		if !ok || kind != "socket" {
+			continue
+		}
+		s, ok := sockets[ino]
+		if !ok {
+			s = Socket{Inode: ino, Proto: "?"}
+		}
+
+		result := FdSocket{Fd: fd, Socket: s}
+		if s.Peer != 0 {
+			if index == nil {
+				index = FdIndex()
+			}
+			result.Peers = index[fmt.Sprintf("socket:[%d]", s.Peer)]
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
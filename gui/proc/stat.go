@@ -0,0 +1,76 @@
+package proc
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Stat is the subset of /proc/<pid>/stat pst uses. Times are in clock
+// ticks, Rss in pages.
+type Stat struct {
+	Comm       string
+	State      string
+	PPid       PID
+	Utime      uint64
+	Stime      uint64
+	NumThreads int
+	StartTime  uint64
+	Rss        int64
+	Processor  int
+}
+
+// ParseStat parses the content of a /proc/<pid>/stat or
+// /proc/<pid>/task/<tid>/stat file.
+func ParseStat(s string) (Stat, error) {
+	// comm may contain spaces and parens, it ends at the last ')'
+	open, end := strings.IndexByte(s, '('), strings.LastIndexByte(s, ')')
+	if open < 0 || end < open {
+		return Stat{}, fmt.Errorf("unable to parse stat '%s'", s)
+	}
+	// fields start at field 3 (state)
+	f := strings.Fields(s[end+1:])
+	if len(f) < 37 {
+		return Stat{}, fmt.Errorf("unable to parse stat '%s'", s)
+	}
+
+	num := func(i int) uint64 {
+		n, _ := strconv.ParseUint(f[i-3], 10, 64)
+		return n
+	}
+	rss, _ := strconv.ParseInt(f[24-3], 10, 64)
+
+	return Stat{
+		Comm:       s[open+1 : end],
+		State:      f[0],
+		PPid:       PID(f[4-3]),
+		Utime:      num(14),
+		Stime:      num(15),
+		NumThreads: int(num(20)),
+		StartTime:  num(22),
+		Rss:        rss,
+		Processor:  int(num(39)),
+	}, nil
+}
+
+func GetStat(pid PID) (Stat, error) {
+	s, err := readProcPath(pid, "stat")
+	if err != nil {
+		return Stat{}, err
+	}
+	return ParseStat(s)
+}
+
+// ProcKey identifies a process across pid reuse.
+type ProcKey struct {
+	Pid   PID
+	Start uint64
+}
+
+func GetProcKey(pid PID) (ProcKey, error) {
+	st, err := GetStat(pid)
+	if err != nil {
+		return ProcKey{}, err
+	}
+	return ProcKey{Pid: pid, Start: st.StartTime}, nil
+}
@@ -0,0 +1,35 @@
+package proc
+
+import (
+	"path"
+	"strings"
+)
+
+// ParseStatus parses the "Key:\tvalue" lines of /proc/<pid>/status.
+func ParseStatus(s string) map[string]string {
+	status := make(map[string]string)
+	for _, line := range strings.Split(s, "\n") {
+		kv := strings.SplitN(line, ":", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		status[kv[0]] = strings.TrimSpace(kv[1])
+	}
+	return status
+}
+
+func GetStatus(pid PID) (map[string]string, error) {
+	s, err := readProcPath(pid, "status")
+	if err != nil {
+		return nil, err
+	}
+	return ParseStatus(s), nil
+}
+
+func GetTaskStatus(pid, tid PID) (map[string]string, error) {
+	s, err := readProcPath(pid, path.Join("task", tid.String(), "status"))
+	if err != nil {
+		return nil, err
+	}
+	return ParseStatus(s), nil
+}
@@ -0,0 +1,207 @@
+package proc
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+const syscallLogSize = 10000
+
+type SyscallData struct {
+	Time     time.Time
+	Tid      PID
+	Nr       int
+	Ret      int64
+	Duration time.Duration
+	Args     [3]uint64
+}
+
+func SyscallName(nr int) string {
+	if name, ok := syscallNames[nr]; ok {
+		return name
+	}
+	return fmt.Sprintf("syscall_%d", nr)
+}
+
+func (d SyscallData) Name() string {
+	return SyscallName(d.Nr)
+}
+
+// Return decodes the return value the way strace does, e.g.
+// "-1 ENOENT (no such file or directory)".
+func (d SyscallData) Return() string {
+	if d.Ret < 0 && d.Ret >= -4095 {
+		errno := syscall.Errno(-d.Ret)
+		return fmt.Sprintf("-1 %s (%s)", unix.ErrnoName(errno), errno.Error())
+	}
+	return strconv.FormatInt(d.Ret, 10)
+}
+
+func (d SyscallData) String() string {
+	return fmt.Sprintf("%s [%s] %s(%#x, %#x, %#x) = %s <%.6f>",
+		d.Time.Format("15:04:05.000000"), d.Tid, d.Name(),
+		d.Args[0], d.Args[1], d.Args[2], d.Return(), d.Duration.Seconds())
+}
+
+func NewSyscallDataSource(filter Filter) (Datasource[SyscallData], error) {
+	const syscallTrace = `
+tracepoint:raw_syscalls:sys_enter
+/*filter*/
+{
+	@sc_start[tid] = nsecs;
+	@sc_a0[tid] = args->args[0];
+	@sc_a1[tid] = args->args[1];
+	@sc_a2[tid] = args->args[2];
+}
+
+tracepoint:raw_syscalls:sys_exit
+/@sc_start[tid]/
+{
+	printf("%d %d %d %d %d %x %x %x\n", pid, tid, args->id, args->ret,
+		nsecs - @sc_start[tid], @sc_a0[tid], @sc_a1[tid], @sc_a2[tid]);
+	delete(@sc_start[tid]);
+	delete(@sc_a0[tid]);
+	delete(@sc_a1[tid]);
+	delete(@sc_a2[tid]);
+}
+
+END
+{
+	clear(@sc_start);
+	clear(@sc_a0);
+	clear(@sc_a1);
+	clear(@sc_a2);
+}
+`
+	return NewSource(syscallTrace, filter, func(line string) (PID, SyscallData, error) {
+		s := strings.Split(strings.TrimSpace(line), " ")
+		if len(s) != 8 {
+			return PID(""), SyscallData{}, fmt.Errorf("unable to parse '%s'\n", line)
+		}
+
+		nr, err := strconv.Atoi(s[2])
+		if err != nil {
+			return PID(""), SyscallData{}, fmt.Errorf("invalid syscall nr '%s'\n", line)
+		}
+		ret, err := strconv.ParseInt(s[3], 10, 64)
+		if err != nil {
+			return PID(""), SyscallData{}, fmt.Errorf("invalid retval '%s'\n", line)
+		}
+		dur, err := strconv.ParseInt(s[4], 10, 64)
+		if err != nil {
+			return PID(""), SyscallData{}, fmt.Errorf("invalid duration '%s'\n", line)
+		}
+
+		d := SyscallData{
+			Time:     time.Now(),
+			Tid:      PID(s[1]),
+			Nr:       nr,
+			Ret:      ret,
+			Duration: time.Duration(dur),
+		}
+		for i := range d.Args {
+			d.Args[i], _ = strconv.ParseUint(s[5+i], 16, 64)
+		}
+		return PID(s[0]), d, nil
+	})
+}
+
+// SyscallStream is an on demand syscall tracer keeping the most recent
+// events in memory.
+type SyscallStream struct {
+	lock    *sync.RWMutex
+	ds      *Datasource[SyscallData]
+	filter  Filter
+	events  []SyscallData
+	dropped int
+}
+
+func NewSyscallStream() *SyscallStream {
+	return &SyscallStream{
+		lock: &sync.RWMutex{},
+	}
+}
+
+// Start (re)starts tracing the processes matched by filter, discarding
+// previously recorded events.
+func (s *SyscallStream) Start(filter Filter) error {
+	s.Stop()
+
+	ds, err := NewSyscallDataSource(filter)
+	if err != nil {
+		return err
+	}
+	pidCh, dataCh, err := ds.GetStream()
+	if err != nil {
+		ds.Close()
+		return err
+	}
+
+	s.lock.Lock()
+	session := &ds
+	s.ds = session
+	s.filter = filter
+	s.events = make([]SyscallData, 0, 1024)
+	s.dropped = 0
+	s.lock.Unlock()
+
+	go func() {
+		for range pidCh {
+			e := <-dataCh
+
+			s.lock.Lock()
+			if s.ds != session {
+				// buffered events of a stopped session
+				s.lock.Unlock()
+				continue
+			}
+			if len(s.events) >= syscallLogSize {
+				n := copy(s.events, s.events[syscallLogSize/2:])
+				s.events = s.events[:n]
+				s.dropped += syscallLogSize - n
+			}
+			s.events = append(s.events, e)
+			s.lock.Unlock()
+		}
+	}()
+	return nil
+}
+
+func (s *SyscallStream) Stop() {
+	s.lock.Lock()
+	ds := s.ds
+	s.ds = nil
+	s.lock.Unlock()
+
+	if ds != nil {
+		ds.Close()
+	}
+}
+
+func (s *SyscallStream) Running() bool {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.ds != nil
+}
+
+func (s *SyscallStream) Filter() Filter {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.filter
+}
+
+// Events returns a copy of the recorded events and how many older events
+// were dropped to bound memory.
+func (s *SyscallStream) Events() ([]SyscallData, int) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	events := make([]SyscallData, len(s.events))
+	copy(events, s.events)
+	return events, s.dropped
+}
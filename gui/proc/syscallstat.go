@@ -0,0 +1,183 @@
+package proc
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SyscallStatData is one line of the periodic map dump of the syscall
+// stats tracer: either a count, a total time or a latency histogram bucket.
+type SyscallStatData struct {
+	Nr    int
+	Count uint64
+	Time  time.Duration
+	// Hist is set for histogram buckets, Bucket being the lower bound of
+	// the bucket and Count the number of syscalls in it.
+	Hist   bool
+	Bucket time.Duration
+}
+
+// SyscallStat is the accumulated stats of a syscall.
+type SyscallStat struct {
+	Nr    int
+	Count uint64
+	Time  time.Duration
+	Hist  map[time.Duration]uint64
+}
+
+func (s SyscallStat) Name() string {
+	return SyscallName(s.Nr)
+}
+
+func (s SyscallStat) Avg() time.Duration {
+	if s.Count == 0 {
+		return 0
+	}
+	return s.Time / time.Duration(s.Count)
+}
+
+// Percentile returns the lower bound of the latency bucket holding the
+// p-th percentile (0 < p <= 1).
+func (s SyscallStat) Percentile(p float64) time.Duration {
+	var total uint64
+	buckets := make([]time.Duration, 0, len(s.Hist))
+	for b, n := range s.Hist {
+		buckets = append(buckets, b)
+		total += n
+	}
+	if total == 0 {
+		return 0
+	}
+	sort.Slice(buckets, func(i, j int) bool { return buckets[i] < buckets[j] })
+
+	target := uint64(float64(total) * p)
+	var seen uint64
+	for _, b := range buckets {
+		seen += s.Hist[b]
+		if seen >= target {
+			return b
+		}
+	}
+	return buckets[len(buckets)-1]
+}
+
+func (s *SyscallStat) add(d SyscallStatData) {
+	if d.Hist {
+		if s.Hist == nil {
+			s.Hist = make(map[time.Duration]uint64)
+		}
+		s.Hist[d.Bucket] += d.Count
+		return
+	}
+	s.Count += d.Count
+	s.Time += d.Time
+}
+
+var (
+	statKeyRe    = regexp.MustCompile(`^@(\w+)\[(\d+), (\d+)\]:\s*(\d*)$`)
+	statBucketRe = regexp.MustCompile(`^\[([0-9KMGT]+)(?:, [0-9KMGT]+\)|\])\s+(\d+)\s*\|`)
+)
+
+func parseHistBound(s string) (uint64, error) {
+	mult := uint64(1)
+	switch {
+	case strings.HasSuffix(s, "K"):
+		mult = 1 << 10
+	case strings.HasSuffix(s, "M"):
+		mult = 1 << 20
+	case strings.HasSuffix(s, "G"):
+		mult = 1 << 30
+	case strings.HasSuffix(s, "T"):
+		mult = 1 << 40
+	}
+	n, err := strconv.ParseUint(strings.TrimRight(s, "KMGT"), 10, 64)
+	return n * mult, err
+}
+
+func NewSyscallStatDataSource(filter Filter) (Datasource[SyscallStatData], error) {
+	const syscallStatTrace = `
+tracepoint:raw_syscalls:sys_enter
+/*filter*/
+{
+	@sys_start[tid] = nsecs;
+}
+
+tracepoint:raw_syscalls:sys_exit
+/@sys_start[tid]/
+{
+	$lat = nsecs - @sys_start[tid];
+	@count[pid, args->id] = count();
+	@time[pid, args->id] = sum($lat);
+	@lat[pid, args->id] = hist($lat);
+	delete(@sys_start[tid]);
+}
+
+interval:s:1
+{
+	print(@count);
+	print(@time);
+	print(@lat);
+	clear(@count);
+	clear(@time);
+	clear(@lat);
+}
+
+END
+{
+	clear(@sys_start);
+	clear(@count);
+	clear(@time);
+	clear(@lat);
+}
+`
+	return NewParserSource(syscallStatTrace, filter, func() func(line string) (PID, SyscallStatData, error) {
+		// histogram buckets follow the "@lat[pid, nr]:" line they belong to
+		var histPid PID
+		histNr := -1
+
+		return func(line string) (PID, SyscallStatData, error) {
+			line = strings.TrimSpace(line)
+
+			if m := statKeyRe.FindStringSubmatch(line); m != nil {
+				name, pid := m[1], PID(m[2])
+				nr, _ := strconv.Atoi(m[3])
+				histNr = -1
+				if name == "lat" {
+					histPid, histNr = pid, nr
+					return PID(""), SyscallStatData{}, fmt.Errorf("histogram header '%s'\n", line)
+				}
+
+				v, err := strconv.ParseUint(m[4], 10, 64)
+				if err != nil {
+					return PID(""), SyscallStatData{}, fmt.Errorf("unable to parse '%s'\n", line)
+				}
+				switch name {
+				case "count":
+					return pid, SyscallStatData{Nr: nr, Count: v}, nil
+				case "time":
+					return pid, SyscallStatData{Nr: nr, Time: time.Duration(v)}, nil
+				}
+			}
+
+			if m := statBucketRe.FindStringSubmatch(line); m != nil && histNr >= 0 {
+				bound, err := parseHistBound(m[1])
+				if err != nil {
+					return PID(""), SyscallStatData{}, fmt.Errorf("unable to parse '%s'\n", line)
+				}
+				n, _ := strconv.ParseUint(m[2], 10, 64)
+				return histPid, SyscallStatData{
+					Nr:     histNr,
+					Count:  n,
+					Hist:   true,
+					Bucket: time.Duration(bound),
+				}, nil
+			}
+
+			return PID(""), SyscallStatData{}, fmt.Errorf("unable to parse '%s'\n", line)
+		}
+	})
+}
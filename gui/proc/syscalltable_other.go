@@ -0,0 +1,7 @@
+//go:build !amd64 && !arm64
+
+package proc
+
+// syscallNames is not known on this architecture, syscalls are shown
+// by number.
+var syscallNames = map[int]string{}
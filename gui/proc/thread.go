@@ -0,0 +1,87 @@
+package proc
+
+import (
+	"io/ioutil"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Thread is a task of a process.
+type Thread struct {
+	Tid PID
+	Stat
+	Wchan               string
+	VoluntarySwitches   uint64
+	InvoluntarySwitches uint64
+}
+
+// GetThreads reads the tasks of pid from /proc/<pid>/task.
+func GetThreads(pid PID) ([]Thread, error) {
+	files, err := ioutil.ReadDir(path.Join("/proc", pid.String(), "task"))
+	if err != nil {
+		return nil, err
+	}
+
+	threads := make([]Thread, 0, len(files))
+	for _, f := range files {
+		tid := PID(f.Name())
+		task := path.Join("task", tid.String())
+
+		s, err := readProcPath(pid, path.Join(task, "stat"))
+		if err != nil {
+			continue
+		}
+		st, err := ParseStat(s)
+		if err != nil {
+			continue
+		}
+		t := Thread{Tid: tid, Stat: st}
+
+		if wchan, err := readProcPath(pid, path.Join(task, "wchan")); err == nil && wchan != "0" {
+			t.Wchan = wchan
+		}
+		if status, err := GetTaskStatus(pid, tid); err == nil {
+			t.VoluntarySwitches, _ = strconv.ParseUint(status["voluntary_ctxt_switches"], 10, 64)
+			t.InvoluntarySwitches, _ = strconv.ParseUint(status["nonvoluntary_ctxt_switches"], 10, 64)
+		}
+		threads = append(threads, t)
+	}
+
+	sort.Slice(threads, func(i, j int) bool {
+		a, b := threads[i].Tid, threads[j].Tid
+		if len(a) == len(b) {
+			return a < b
+		}
+		return len(a) < len(b)
+	})
+	return threads, nil
+}
+
+// GetKernelStack reads the kernel stack of a task, only readable by root.
+func GetKernelStack(pid, tid PID) (string, error) {
+	s, err := readProcPath(pid, path.Join("task", tid.String(), "stack"))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(s, "\n"), nil
+}
+
+// GetWchan returns the kernel function pid sleeps in, "" when running.
+func GetWchan(pid PID) string {
+	wchan, err := readProcPath(pid, "wchan")
+	if err != nil || wchan == "0" {
+		return ""
+	}
+	return wchan
+}
+
+// GetProcessStack reads the kernel stack of pid, only readable by root.
+func GetProcessStack(pid PID) (string, error) {
+	s, err := readProcPath(pid, "stack")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(s, "\n"), nil
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"path"
 	"path/filepath"
+	"strconv"
 	"strings"
 )
 
@@ -19,7 +20,7 @@ tracepoint:syscalls:sys_enter_exec*
     printf("%d %d %s\n", curtask->real_parent->tgid, pid, str(args->argv[0]));
 }
 `
-	return NewSource(execTrace, func(line string) (PID, ExecData, error) {
+	return NewSource(execTrace, Filter{}, func(line string) (PID, ExecData, error) {
 		s := strings.SplitN(strings.TrimSpace(line), " ", 3)
 		if len(s) != 3 {
 			return PID(""), ExecData{}, fmt.Errorf("unable to parse '%s'\n", line)
@@ -33,25 +34,57 @@ tracepoint:syscalls:sys_enter_exec*
 }
 
 type ChdirData struct {
+	// Time is the kernel timestamp (nsecs) of the syscall.
+	Time uint64
+	// Cwd is empty when the new working directory is not known from the
+	// trace alone (e.g. after fchdir), callers should fall back to /proc.
 	Cwd string
 }
 
-func NewChdirDataSource() (Datasource[ChdirData], error) {
+func NewChdirDataSource(filter Filter) (Datasource[ChdirData], error) {
 	const chdirTrace = `
-tracepoint:syscalls:sys_enter_chdir*
+tracepoint:syscalls:sys_enter_chdir
+/*filter*/
 {
-    printf("%d %s\n", pid, str(args->filename));
+	@chdir[tid] = args->filename;
+}
+
+tracepoint:syscalls:sys_exit_chdir
+/@chdir[tid]/
+{
+	if (args->ret == 0) {
+		printf("%d %llu %s\n", pid, nsecs, str(@chdir[tid]));
+	}
+	delete(@chdir[tid]);
+}
+
+tracepoint:syscalls:sys_exit_fchdir
+/*filter*/
+{
+	if (args->ret == 0) {
+		printf("%d %llu\n", pid, nsecs);
+	}
+}
+
+END
+{
+	clear(@chdir);
 }
 `
-	return NewSource(chdirTrace, func(line string) (PID, ChdirData, error) {
-		s := strings.SplitN(strings.TrimSpace(line), " ", 2)
-		if len(s) != 2 {
+	return NewSource(chdirTrace, filter, func(line string) (PID, ChdirData, error) {
+		s := strings.SplitN(strings.TrimSpace(line), " ", 3)
+		if len(s) < 2 {
 			return PID(""), ChdirData{}, fmt.Errorf("unable to parse '%s'\n", line)
 		}
-		pid, cwd := PID(s[0]), s[1]
-		return pid, ChdirData{
-			Cwd: cwd,
-		}, nil
+		t, err := strconv.ParseUint(s[1], 10, 64)
+		if err != nil {
+			return PID(""), ChdirData{}, fmt.Errorf("invalid time '%s'\n", line)
+		}
+		d := ChdirData{Time: t}
+		if len(s) == 3 {
+			d.Cwd = s[2]
+		}
+		return PID(s[0]), d, nil
 	}, func(pid PID) (ChdirData, error) {
 		path, err := filepath.EvalSymlinks(path.Join("/proc", pid.String(), "cwd"))
 		if err != nil {
@@ -64,16 +97,34 @@ tracepoint:syscalls:sys_enter_chdir*
 	)
 }
 
+// AtFdCwd is the dirfd value meaning "relative to the working directory".
+const AtFdCwd = -100
+
 type OpenData struct {
+	// Time is the kernel timestamp (nsecs) of the syscall, used to resolve
+	// relative paths against the cwd the process had at that moment.
+	Time uint64
+	// Dirfd is the directory fd the path was opened relative to.
+	Dirfd int
+	// Filepath is the path as passed to the syscall, procDataSource
+	// resolves it to an absolute path before recording it.
 	Filepath string
 }
 
-func NewOpenDataSource() (Datasource[OpenData], error) {
+func NewOpenDataSource(filter Filter) (Datasource[OpenData], error) {
 	const openTrace = `
-tracepoint:syscalls:sys_enter_open,
+tracepoint:syscalls:sys_enter_open
+/*filter*/
+{
+	@filename[tid] = args->filename;
+	@dirfd[tid] = -100;
+}
+
 tracepoint:syscalls:sys_enter_openat
+/*filter*/
 {
 	@filename[tid] = args->filename;
+	@dirfd[tid] = args->dfd;
 }
 
 tracepoint:syscalls:sys_exit_open,
@@ -83,32 +134,46 @@ tracepoint:syscalls:sys_exit_openat
 	$ret = args->ret;
 	$fd = $ret > 0 ? $ret : -1;
 
-	printf("%d %d %s\t\n", pid, $fd, str(@filename[tid]));
+	printf("%d %d %d %llu %s\t\n", pid, $fd, @dirfd[tid], nsecs, str(@filename[tid]));
 	delete(@filename[tid]);
+	delete(@dirfd[tid]);
 }
 
 END
 {
 	clear(@filename);
+	clear(@dirfd);
 }
 `
-	return NewSource(openTrace, func(line string) (PID, OpenData, error) {
-		s := strings.SplitN(strings.TrimSpace(line), " ", 3)
-		if len(s) != 3 {
+	return NewSource(openTrace, filter, func(line string) (PID, OpenData, error) {
+		s := strings.SplitN(strings.TrimSpace(line), " ", 5)
+		if len(s) != 5 {
 			return PID(""), OpenData{}, fmt.Errorf("unable to parse '%s'\n", line)
 		}
 
-		pid, retval, filepath := PID(s[0]), s[1], s[2]
+		pid, retval, filepath := PID(s[0]), s[1], s[4]
 
 		if retval == "-1" {
 			return PID(""), OpenData{}, fmt.Errorf("open retval -1 '%s'\n", line)
 		}
 
+		dirfd, err := strconv.Atoi(s[2])
+		if err != nil {
+			return PID(""), OpenData{}, fmt.Errorf("invalid dirfd '%s'\n", line)
+		}
+
+		t, err := strconv.ParseUint(s[3], 10, 64)
+		if err != nil {
+			return PID(""), OpenData{}, fmt.Errorf("invalid time '%s'\n", line)
+		}
+
 		if len(filepath) == 200-1 {
 			filepath = filepath + "<...>"
 		}
 
 		return pid, OpenData{
+			Time:     t,
+			Dirfd:    dirfd,
 			Filepath: filepath,
 		}, nil
 	})
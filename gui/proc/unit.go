@@ -0,0 +1,53 @@
+package proc
+
+import "strings"
+
+var unitSuffixes = []string{".service", ".scope", ".slice"}
+
+// ParseUnit returns the systemd unit owning the cgroup at cgroupPath: the
+// innermost service or scope, or the innermost slice for processes
+// directly in a slice. It returns "" outside of systemd's hierarchy.
+func ParseUnit(cgroupPath string) string {
+	slice := ""
+	elems := strings.Split(cgroupPath, "/")
+	for i := len(elems) - 1; i >= 0; i-- {
+		elem := elems[i]
+		if strings.HasSuffix(elem, ".service") || strings.HasSuffix(elem, ".scope") {
+			return elem
+		}
+		if slice == "" && strings.HasSuffix(elem, ".slice") {
+			slice = elem
+		}
+	}
+	return slice
+}
+
+// UnitType returns "service", "scope" or "slice".
+func UnitType(unit string) string {
+	for _, suffix := range unitSuffixes {
+		if strings.HasSuffix(unit, suffix) {
+			return suffix[1:]
+		}
+	}
+	return ""
+}
+
+// GetUnit returns the systemd unit of pid, "" if it can't be determined.
+func GetUnit(pid PID) string {
+	path, err := GetCgroupPath(pid)
+	if err != nil {
+		return ""
+	}
+	return ParseUnit(path)
+}
+
+// UnitPids lists the processes in unit.
+func UnitPids(unit string) []PID {
+	var pids []PID
+	for _, pid := range Pids() {
+		if GetUnit(pid) == unit {
+			pids = append(pids, pid)
+		}
+	}
+	return pids
+}
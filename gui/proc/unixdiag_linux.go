@@ -0,0 +1,89 @@
+package proc
+
+import (
+	"fmt"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// from linux/sock_diag.h and linux/unix_diag.h
+const (
+	sockDiagByFamily = 20
+	unixDiagShowPeer = 0x4
+	unixDiagPeer     = 2
+
+	unixDiagReqLen = 24
+	unixDiagMsgLen = 16
+)
+
+// UnixPeers asks the kernel (sock_diag) for the peer of every connected
+// unix socket of pst's network namespace, mapping socket inodes to the
+// inode of their peer.
+func UnixPeers() (map[uint64]uint64, error) {
+	fd, err := unix.Socket(unix.AF_NETLINK, unix.SOCK_DGRAM|unix.SOCK_CLOEXEC, unix.NETLINK_SOCK_DIAG)
+	if err != nil {
+		return nil, err
+	}
+	defer unix.Close(fd)
+
+	// struct nlmsghdr followed by struct unix_diag_req
+	req := make([]byte, unix.SizeofNlMsghdr+unixDiagReqLen)
+	nativeEndian.PutUint32(req[0:], uint32(len(req)))
+	nativeEndian.PutUint16(req[4:], sockDiagByFamily)
+	nativeEndian.PutUint16(req[6:], unix.NLM_F_REQUEST|unix.NLM_F_DUMP)
+	nativeEndian.PutUint32(req[8:], 1)
+	body := req[unix.SizeofNlMsghdr:]
+	body[0] = unix.AF_UNIX
+	nativeEndian.PutUint32(body[4:], 0xffffffff) // all states
+	nativeEndian.PutUint32(body[12:], unixDiagShowPeer)
+	nativeEndian.PutUint32(body[16:], 0xffffffff) // no cookie
+	nativeEndian.PutUint32(body[20:], 0xffffffff)
+
+	if err := unix.Sendto(fd, req, 0, &unix.SockaddrNetlink{Family: unix.AF_NETLINK}); err != nil {
+		return nil, err
+	}
+
+	peers := make(map[uint64]uint64)
+	buf := make([]byte, 1<<16)
+	for {
+		n, _, err := unix.Recvfrom(fd, buf, 0)
+		if err != nil {
+			return nil, err
+		}
+		msgs, err := syscall.ParseNetlinkMessage(buf[:n])
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range msgs {
+			switch m.Header.Type {
+			case unix.NLMSG_DONE:
+				return peers, nil
+			case unix.NLMSG_ERROR:
+				return nil, fmt.Errorf("sock_diag: netlink error")
+			}
+			if len(m.Data) < unixDiagMsgLen {
+				continue
+			}
+			ino := uint64(nativeEndian.Uint32(m.Data[4:]))
+
+			// rtattrs follow struct unix_diag_msg
+			attrs := m.Data[unixDiagMsgLen:]
+			for len(attrs) >= unix.SizeofRtAttr {
+				l := int(nativeEndian.Uint16(attrs[0:]))
+				t := nativeEndian.Uint16(attrs[2:])
+				if l < unix.SizeofRtAttr || l > len(attrs) {
+					break
+				}
+				if t == unixDiagPeer && l >= unix.SizeofRtAttr+4 {
+					peers[ino] = uint64(nativeEndian.Uint32(attrs[unix.SizeofRtAttr:]))
+				}
+				aligned := (l + unix.RTA_ALIGNTO - 1) &^ (unix.RTA_ALIGNTO - 1)
+				if aligned > len(attrs) {
+					break
+				}
+				attrs = attrs[aligned:]
+			}
+		}
+	}
+}
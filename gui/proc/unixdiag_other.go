@@ -0,0 +1,8 @@
+//go:build !linux
+
+package proc
+
+// UnixPeers needs sock_diag, without it no peers are known.
+func UnixPeers() (map[uint64]uint64, error) {
+	return map[uint64]uint64{}, nil
+}
@@ -36,7 +36,54 @@ func (p *ProcessInfoView) UpdateInfoWithPid(g *Gui, pid proc.PID) {
 		}
 	}
 
+	if cpu, ok := g.ProcessManager.GetCPU(pid); ok {
+		text += fmt.Sprintf("\ncpu now: %.1f%%", cpu)
+	}
+
+	if cgroups, err := proc.GetCgroups(pid); err == nil && len(cgroups) > 0 {
+		text += "\ncgroup: " + proc.CgroupPath(cgroups)
+		if stats := formatCgroupStats(proc.GetCgroupStats(cgroups)); stats != "" {
+			text += " (" + stats + ")"
+		}
+	}
+
+	if unit := proc.GetUnit(pid); unit != "" {
+		text += "\nunit: " + unit
+	}
+
+	if c, ok := proc.GetContainer(pid); ok {
+		text += fmt.Sprintf("\ncontainer: %s (%s)", c.ShortID(), c.Runtime)
+		if c.PodUID != "" {
+			text += " pod: " + c.PodUID
+		}
+	}
+
+	if nspid, err := proc.GetNSpid(pid); err == nil && len(nspid) > 1 {
+		text += fmt.Sprintf("\npid in namespace: %s", nspid[len(nspid)-1])
+	}
+	if namespaces := proc.GetNamespaces(pid); len(namespaces) > 0 {
+		ns := make([]string, 0, len(namespaces))
+		for _, t := range proc.NamespaceTypes {
+			if inode, ok := namespaces[t]; ok {
+				ns = append(ns, fmt.Sprintf("%s:%d", t, inode))
+			}
+		}
+		text += "\nnamespaces: " + strings.Join(ns, " ")
+	}
+
+	if files, err := proc.DeletedFiles(pid); err == nil && len(files) > 0 {
+		text += fmt.Sprintf("\n[red]deleted files held open: %d, %s[white]",
+			len(files), formatBytes(proc.DeletedSize(files)))
+	}
+
+	history := g.ProcessManager.GetHistory(pid)
+
 	g.App.QueueUpdateDraw(func() {
+		_, _, width, _ := p.GetInnerRect()
+		if h := renderHistory(history, width); h != "" {
+			p.SetText(text + "\n" + h)
+			return
+		}
 		p.SetText(text)
 	})
 
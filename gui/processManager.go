@@ -1,6 +1,7 @@
 package gui
 
 import (
+	"fmt"
 	"sort"
 
 	"github.com/dixler/pst/gui/proc"
@@ -12,13 +13,18 @@ type ProcessManager struct {
 	*tview.Table
 	pids       *[]proc.PID
 	FilterWord string
-	procDs     proc.ProcDataSource
+	// SortByCPU orders the table by cpu usage instead of pid.
+	SortByCPU bool
+	// grouping splits the table into groups, nil listing processes flat.
+	grouping *Grouping
+	tracing  string
+	procDs   proc.ProcDataSource
 }
 
 func NewProcessManager() *ProcessManager {
 	procDs, err := proc.NewProcDataSource()
 	if err != nil {
-		panic("")
+		panic(fmt.Sprintf("unable to read processes: %v", err))
 	}
 
 	p := &ProcessManager{
@@ -26,7 +32,8 @@ func NewProcessManager() *ProcessManager {
 		Table:  tview.NewTable().Select(0, 0).SetFixed(1, 1).SetSelectable(true, false),
 		procDs: procDs,
 	}
-	p.SetBorder(true).SetTitle("processes").SetTitleAlign(tview.AlignLeft)
+	p.SetBorder(true).SetTitleAlign(tview.AlignLeft)
+	p.updateTitle()
 	return p
 }
 
@@ -45,22 +52,201 @@ func (p *ProcessManager) GetProcesses() (map[proc.PID]proc.Process, error) {
 	return procmap, nil
 }
 
+// TraceError returns why the tracers are not running, nil if they are.
+func (p *ProcessManager) TraceError() error {
+	return p.procDs.TraceError()
+}
+
+// Trace restricts the open and chdir tracers to filter.
+func (p *ProcessManager) Trace(filter proc.Filter) error {
+	return p.procDs.SetTraceFilter(filter)
+}
+
+func (p *ProcessManager) AddProbe(probe proc.Probe) (*proc.ProbeSource, error) {
+	return p.procDs.AddProbe(probe)
+}
+
+func (p *ProcessManager) GetSyscallStats(pids ...proc.PID) []proc.SyscallStat {
+	return p.procDs.GetSyscallStats(pids...)
+}
+
+func (p *ProcessManager) GetNetTrace(pid proc.PID) []proc.NetConn {
+	return p.procDs.GetNetTrace(pid)
+}
+
+func (p *ProcessManager) GetFdTrend(pid proc.PID) proc.FdTrend {
+	return p.procDs.GetFdTrend(pid)
+}
+
+func (p *ProcessManager) GetFdCount(pid proc.PID) (int, bool) {
+	return p.procDs.GetFdCount(pid)
+}
+
+func (p *ProcessManager) GetCPU(pid proc.PID) (float64, bool) {
+	return p.procDs.GetCPU(pid)
+}
+
+func (p *ProcessManager) GetThreadCPU(tid proc.PID) (float64, bool) {
+	return p.procDs.GetThreadCPU(tid)
+}
+
+func (p *ProcessManager) GetHistory(pid proc.PID) []proc.HistorySample {
+	return p.procDs.GetHistory(pid)
+}
+
+func (p *ProcessManager) GetStuckTasks() []proc.StuckTask {
+	return p.procDs.GetStuckTasks()
+}
+
+// SetTracing shows in the title what the tracers are pinned to, or clears
+// it if target is empty.
+func (p *ProcessManager) SetTracing(target string) {
+	p.tracing = target
+	p.updateTitle()
+}
+
+func (p *ProcessManager) updateTitle() {
+	title := "processes"
+	if p.SortByCPU {
+		title += " [by cpu]"
+	}
+	if p.grouping != nil {
+		title += fmt.Sprintf(" [group by %s]", p.grouping.Name)
+	}
+	if err := p.procDs.TraceError(); err != nil {
+		title += fmt.Sprintf(" [tracing off: %v]", err)
+	} else if p.tracing != "" {
+		title += fmt.Sprintf(" [tracing %s]", p.tracing)
+	}
+	p.SetTitle(title)
+}
+
 var headers = []string{
 	"Pid",
+	"CPU%",
 	"Cmd",
 }
 
-func (p *ProcessManager) UpdateView() error {
-	// get processes
-	procs, err := p.GetProcesses()
-	if err != nil {
-		return err
+// containerHeaders are inserted before Cmd when a listed process runs
+// in a container.
+var containerHeaders = []string{
+	"Container",
+	"Runtime",
+	"Pod",
+}
+
+// processRows is a snapshot of the process table, read from /proc by
+// collectRows and put into the table by setRows.
+type processRows struct {
+	view   rowView
+	cols   []string
+	rows   []proc.PID
+	labels map[int]string
+	cells  map[proc.PID][]string
+	near   map[proc.PID]bool
+}
+
+// rowView is what the rows are filtered, sorted and grouped by.
+type rowView struct {
+	filter   string
+	byCPU    bool
+	grouping *Grouping
+}
+
+func (p *ProcessManager) rowView() rowView {
+	return rowView{filter: p.FilterWord, byCPU: p.SortByCPU, grouping: p.grouping}
+}
+
+// collectRows reads the processes listed by view. It does not touch the
+// table, so it can run off the UI goroutine.
+func (p *ProcessManager) collectRows(view rowView) *processRows {
+	procs := p.procDs.GetProcesses(view.filter)
+
+	pids := make([]proc.PID, 0, len(procs))
+	for _, proc := range procs {
+		pids = append(pids, proc.Pid)
+	}
+
+	cpu := make(map[proc.PID]float64, len(pids))
+	for _, pid := range pids {
+		if u, ok := p.procDs.GetCPU(pid); ok {
+			cpu[pid] = u
+		} else {
+			cpu[pid] = -1
+		}
+	}
+
+	sort.Slice(pids, func(i, j int) bool {
+		a, b := pids[i], pids[j]
+
+		if view.byCPU && cpu[a] != cpu[b] {
+			return cpu[a] > cpu[b]
+		}
+		if len(a) == len(b) {
+			return a < b
+		}
+		return len(a) < len(b)
+	})
+
+	containers := make(map[proc.PID]proc.Container)
+	for _, pid := range pids {
+		if c, ok := proc.GetContainer(pid); ok {
+			containers[pid] = c
+		}
+	}
+	cols := headers
+	if len(containers) > 0 {
+		cols = append(append(append([]string{}, headers[:2]...), containerHeaders...), headers[2:]...)
+	}
+
+	r := &processRows{
+		view:  view,
+		cols:  cols,
+		rows:  pids,
+		cells: make(map[proc.PID][]string, len(pids)),
+		near:  make(map[proc.PID]bool),
+	}
+	if view.grouping != nil {
+		r.rows, r.labels = view.grouping.groupRows(pids)
+	}
+
+	for _, proc := range procs {
+		pid := proc.Pid
+		usage := "-"
+		if cpu[pid] >= 0 {
+			usage = fmt.Sprintf("%.1f", cpu[pid])
+		}
+		cells := []string{string(pid), usage}
+		if len(containers) > 0 {
+			c := containers[pid]
+			for _, v := range []string{c.ShortID(), c.Runtime, c.PodUID} {
+				if v == "" {
+					v = "-"
+				}
+				cells = append(cells, v)
+			}
+		}
+		r.cells[pid] = append(cells, proc.Cmd)
+		if p.procDs.NearLimit(pid) {
+			r.near[pid] = true
+		}
+	}
+	return r
+}
+
+// setRows puts r into the table, keeping the selected process selected.
+func (p *ProcessManager) setRows(r *processRows) {
+	p.updateTitle()
+
+	var selected proc.PID
+	if sp := p.Selected(); sp != nil {
+		selected = sp.Pid
 	}
 
 	table := p.Clear()
 
 	// set headers
-	for i, h := range headers {
+	for i, h := range r.cols {
 		table.SetCell(0, i, &tview.TableCell{
 			Text:            h,
 			NotSelectable:   true,
@@ -70,31 +256,106 @@ func (p *ProcessManager) UpdateView() error {
 		})
 	}
 
-	// set process info to cell
-	pids := make([]proc.PID, 0, len(procs))
-	for pid := range procs {
-		pids = append(pids, pid)
+	cmdCol := len(r.cols) - 1
+	for i, pid := range r.rows {
+		if label, ok := r.labels[i]; ok {
+			for col := 0; col < cmdCol; col++ {
+				table.SetCell(i+1, col, tview.NewTableCell("").SetSelectable(false))
+			}
+			table.SetCell(i+1, cmdCol, tview.NewTableCell(label).SetSelectable(false).SetTextColor(tcell.ColorGreen))
+			continue
+		}
+		for col, v := range r.cells[pid] {
+			cell := tview.NewTableCell(v)
+			if col == 1 {
+				cell.SetAlign(tview.AlignRight)
+			}
+			if r.near[pid] {
+				cell.SetTextColor(tcell.ColorRed)
+			}
+			table.SetCell(i+1, col, cell)
+		}
 	}
 
-	sort.Slice(pids, func(i, j int) bool {
-		a, b := pids[i], pids[j]
+	rows := r.rows
+	p.pids = &rows
+	if selected == "" || !p.SelectPid(selected) {
+		p.clampSelection()
+	}
+}
 
-		if len(a) == len(b) {
-			return a < b
-		}
-		return len(a) < len(b)
-	})
+// UpdateView rebuilds the table on the calling goroutine, used when the
+// filter, sort or grouping changed and the table has to follow at once.
+func (p *ProcessManager) UpdateView() error {
+	p.setRows(p.collectRows(p.rowView()))
+	return nil
+}
 
-	for i, pid := range pids {
-		proc := procs[pid]
-		pid := string(proc.Pid)
-		table.SetCell(i+1, 0, tview.NewTableCell(pid))
-		table.SetCell(i+1, 1, tview.NewTableCell(proc.Cmd))
+// CycleGrouping switches to the next grouping, the last one going back
+// to a flat list.
+func (p *ProcessManager) CycleGrouping() {
+	switch {
+	case p.grouping == nil:
+		p.grouping = &groupings[0]
+	case p.grouping == &groupings[len(groupings)-1]:
+		p.grouping = nil
+	default:
+		for i := range groupings {
+			if p.grouping == &groupings[i] {
+				p.grouping = &groupings[i+1]
+				break
+			}
+		}
 	}
+	p.updateTitle()
+	p.UpdateView()
+}
 
-	p.pids = &pids
+// ToggleSortByCPU switches the table between pid and cpu usage order.
+func (p *ProcessManager) ToggleSortByCPU() {
+	p.SortByCPU = !p.SortByCPU
+	p.updateTitle()
+	p.UpdateView()
+}
 
-	return nil
+// clampSelection keeps the cursor on a process row after the table
+// shrank, moving it off group rows.
+func (p *ProcessManager) clampSelection() {
+	rows := *p.pids
+	row, _ := p.GetSelection()
+	if row > len(rows) {
+		row = len(rows)
+	}
+	if row < 1 {
+		row = 1
+	}
+	for i := row; i <= len(rows); i++ {
+		if rows[i-1] != "" {
+			p.Select(i, 0)
+			return
+		}
+	}
+	for i := row - 1; i >= 1; i-- {
+		if rows[i-1] != "" {
+			p.Select(i, 0)
+			return
+		}
+	}
+}
+
+// SelectPid moves the selection to pid, returning false if it is not
+// in the table.
+func (p *ProcessManager) SelectPid(pid proc.PID) bool {
+	if p.pids == nil {
+		return false
+	}
+	for i, candidate := range *p.pids {
+		if candidate == pid {
+			p.Select(i+1, 0)
+			return true
+		}
+	}
+	return false
 }
 
 func (p *ProcessManager) Selected() *proc.Process {
@@ -105,9 +366,13 @@ func (p *ProcessManager) Selected() *proc.Process {
 	if row < 0 {
 		return nil
 	}
-	if len(*p.pids) < row {
+	if row < 1 || len(*p.pids) < row {
 		return nil
 	}
 	focusedPid := (*p.pids)[row-1]
+	if focusedPid == "" {
+		// group row
+		return nil
+	}
 	return p.procDs.GetProcess(focusedPid)
 }
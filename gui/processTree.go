@@ -17,6 +17,9 @@ type ProcessTreeView struct {
 	*tview.TreeView
 	getProcess func(proc.PID) *proc.Process
 	pidMap     map[proc.PID]*ProcessNode
+	// unitMode roots the tree at the systemd unit of the process.
+	unitMode bool
+	unit     string
 }
 
 func NewProcessTreeView(
@@ -47,15 +50,32 @@ func (p *ProcessTreeView) ExpandToggle(node *tview.TreeNode, isExpand bool) {
 	}
 }
 
+// ToggleUnitRoot switches between rooting the tree at the process and at
+// its systemd unit.
+func (p *ProcessTreeView) ToggleUnitRoot() {
+	p.unitMode = !p.unitMode
+	p.unit = ""
+	p.pidMap = make(map[proc.PID]*ProcessNode)
+	p.SetRoot(nil)
+	if p.unitMode {
+		p.SetTitle("process tree [unit]")
+	} else {
+		p.SetTitle("process tree")
+	}
+}
+
 func (p *ProcessTreeView) UpdateTree(pid proc.PID) {
+	if p.unitMode {
+		p.updateUnitTree(pid)
+		return
+	}
 	ps := p.getProcess(pid)
 	if ps == nil {
 		return
 	}
 	curRoot := p.GetRoot()
 	if curRoot != nil {
-		rootPid := curRoot.GetReference().(proc.PID)
-		if rootPid == pid {
+		if rootPid, ok := curRoot.GetReference().(proc.PID); ok && rootPid == pid {
 			return
 		}
 	}
@@ -79,6 +99,47 @@ func (p *ProcessTreeView) UpdateTree(pid proc.PID) {
 	p.pidMap[pid] = root
 }
 
+// updateUnitTree roots the tree at the unit of pid, its children being
+// the processes of the unit whose parent is not in the unit. A process
+// without a unit is shown alone under a "(no unit)" root.
+func (p *ProcessTreeView) updateUnitTree(pid proc.PID) {
+	unit := proc.GetUnit(pid)
+	key, label := unit, unit
+	if unit == "" {
+		key, label = "(no unit) "+pid.String(), "(no unit)"
+	}
+	if p.GetRoot() != nil && key == p.unit {
+		return
+	}
+	p.unit = key
+	p.pidMap = make(map[proc.PID]*ProcessNode)
+
+	members := []proc.PID{pid}
+	if unit != "" {
+		members = proc.UnitPids(unit)
+	}
+	inUnit := make(map[proc.PID]bool, len(members))
+	for _, m := range members {
+		inUnit[m] = true
+	}
+
+	root := tview.NewTreeNode(label).SetColor(tcell.ColorYellow)
+	for _, m := range members {
+		if st, err := proc.GetStat(m); err == nil && inUnit[st.PPid] {
+			continue
+		}
+		node := tview.
+			NewTreeNode(fmt.Sprintf("[%s] %s", m, proc.GetCommand(m))).
+			SetReference(m)
+		if ps := p.getProcess(m); ps != nil && len(ps.Child) > 0 {
+			node.SetColor(tcell.ColorGreen)
+		}
+		root.AddChild(node)
+		p.pidMap[m] = &ProcessNode{node: node}
+	}
+	p.SetRoot(root).SetCurrentNode(root)
+}
+
 func (p *ProcessTreeView) addNode(target *tview.TreeNode, pid proc.PID) {
 	pro := p.getProcess(pid)
 	if pro == nil {
@@ -0,0 +1,112 @@
+package gui
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dixler/pst/gui/proc"
+	"github.com/rivo/tview"
+)
+
+// profileTopStacks is how many stacks the panel shows.
+const profileTopStacks = 20
+
+// ProfileView samples the on-cpu stacks of a process or subtree and shows
+// the most frequent ones.
+type ProfileView struct {
+	*tview.TextView
+	lock    *sync.Mutex
+	running bool
+	profile *proc.Profile
+}
+
+func NewProfileView() *ProfileView {
+	p := &ProfileView{
+		TextView: tview.NewTextView().SetDynamicColors(true),
+		lock:     &sync.Mutex{},
+	}
+	p.SetTitleAlign(tview.AlignLeft).SetTitle("profile").SetBorder(true)
+	p.SetWrap(false)
+	p.SetText("press s to profile the selected process, c to profile its subtree")
+	return p
+}
+
+// Profile returns the last completed profile.
+func (p *ProfileView) Profile() *proc.Profile {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return p.profile
+}
+
+// Start profiles pids for duration in the background, showing the result
+// when done.
+func (p *ProfileView) Start(g *Gui, pids []proc.PID, duration time.Duration) error {
+	p.lock.Lock()
+	if p.running {
+		p.lock.Unlock()
+		return fmt.Errorf("a profile is already running")
+	}
+	p.running = true
+	p.lock.Unlock()
+
+	p.SetTitle(fmt.Sprintf("profile [sampling %s for %s]", describePids(pids), duration))
+	go func() {
+		profile, err := proc.RunProfile(pids, duration)
+
+		p.lock.Lock()
+		p.running = false
+		if err == nil {
+			p.profile = &profile
+		}
+		p.lock.Unlock()
+
+		g.App.QueueUpdateDraw(func() {
+			if err != nil {
+				p.SetTitle("profile")
+				p.SetText(err.Error())
+				return
+			}
+			p.SetTitle(fmt.Sprintf("profile [%s, %s, %d samples]",
+				describePids(pids), profile.Duration, profile.Samples))
+			p.SetText(renderProfile(profile))
+			p.ScrollToBeginning()
+		})
+	}()
+	return nil
+}
+
+func describePids(pids []proc.PID) string {
+	if len(pids) == 1 {
+		return "pid " + pids[0].String()
+	}
+	return fmt.Sprintf("pid %s and %d more", pids[0], len(pids)-1)
+}
+
+// UpdateViewWithPid does nothing, the profile only changes when a
+// profiling run ends.
+func (p *ProfileView) UpdateViewWithPid(g *Gui, pid proc.PID) {}
+
+func renderProfile(profile proc.Profile) string {
+	if len(profile.Stacks) == 0 {
+		return "no samples, the process didn't run on cpu"
+	}
+	var b strings.Builder
+	for i, s := range profile.Stacks {
+		if i == profileTopStacks {
+			fmt.Fprintf(&b, "%d more stacks, save the profile to see them all\n", len(profile.Stacks)-i)
+			break
+		}
+		fmt.Fprintf(&b, "[yellow]%d samples (%.1f%%) %s %s[white]\n",
+			s.Count, float64(s.Count)/float64(profile.Samples)*100, s.Pid, s.Comm)
+		for _, f := range s.Kernel {
+			fmt.Fprintf(&b, "  [red]%s[white]\n", f)
+		}
+		for _, f := range s.User {
+			fmt.Fprintf(&b, "  %s\n", f)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
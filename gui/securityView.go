@@ -0,0 +1,72 @@
+package gui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dixler/pst/gui/proc"
+	"github.com/rivo/tview"
+)
+
+// SecurityView shows the capabilities, seccomp mode, no_new_privs and
+// LSM label of a process.
+type SecurityView struct {
+	*tview.TextView
+}
+
+func NewSecurityView() *SecurityView {
+	p := &SecurityView{
+		TextView: tview.NewTextView().SetDynamicColors(true),
+	}
+	p.SetTitleAlign(tview.AlignLeft).SetTitle("process security").SetBorder(true)
+	return p
+}
+
+func (p *SecurityView) UpdateViewWithPid(g *Gui, pid proc.PID) {
+	text := ""
+	sec, err := proc.GetSecurity(pid)
+	if err != nil {
+		text = err.Error()
+	} else {
+		text = renderSecurity(sec)
+	}
+
+	g.App.QueueUpdateDraw(func() {
+		p.SetText(text)
+	})
+}
+
+func renderSecurity(sec proc.Security) string {
+	label := tview.Escape(sec.Label)
+	if label == "" {
+		label = "-"
+	}
+	seccomp := sec.Seccomp
+	if seccomp == "" {
+		seccomp = "-"
+	}
+	nnp := "no"
+	if sec.NoNewPrivs {
+		nnp = "yes"
+	}
+
+	text := fmt.Sprintf("[yellow]seccomp:[white] %s  [yellow]no_new_privs:[white] %s  [yellow]label:[white] %s\n",
+		seccomp, nnp, label)
+	for _, name := range proc.CapSetNames {
+		caps, ok := sec.Caps[name]
+		if !ok {
+			continue
+		}
+		names := ""
+		switch {
+		case caps == 0:
+			names = "-"
+		case caps.Full():
+			names = "[red]all[white]"
+		default:
+			names = strings.Join(caps.Names(), " ")
+		}
+		text += fmt.Sprintf("[yellow]%s:[white] %016x %s\n", name, uint64(caps), names)
+	}
+	return text
+}
@@ -0,0 +1,100 @@
+package gui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dixler/pst/gui/proc"
+)
+
+var sparkBars = []rune("▁▂▃▄▅▆▇█")
+
+// sparkline draws values in at most width cells, each cell showing the
+// largest value of the samples it covers. Negative values are unknown
+// and drawn as blanks.
+func sparkline(values []float64, width int) string {
+	if width <= 0 || len(values) == 0 {
+		return ""
+	}
+	if len(values) < width {
+		width = len(values)
+	}
+
+	cells := make([]float64, width)
+	for i := range cells {
+		cells[i] = -1
+	}
+	for i, v := range values {
+		c := i * width / len(values)
+		if v > cells[c] {
+			cells[c] = v
+		}
+	}
+
+	max := 0.0
+	for _, v := range cells {
+		if v > max {
+			max = v
+		}
+	}
+
+	var b strings.Builder
+	for _, v := range cells {
+		switch {
+		case v < 0:
+			b.WriteRune(' ')
+		case max == 0:
+			b.WriteRune(sparkBars[0])
+		default:
+			b.WriteRune(sparkBars[int(v/max*float64(len(sparkBars)-1)+0.5)])
+		}
+	}
+	return b.String()
+}
+
+// renderHistory draws a sparkline per resource, width being the cells
+// available on a line.
+func renderHistory(samples []proc.HistorySample, width int) string {
+	if len(samples) == 0 {
+		return ""
+	}
+	cpu := make([]float64, len(samples))
+	rss := make([]float64, len(samples))
+	fds := make([]float64, len(samples))
+	io := make([]float64, len(samples))
+	for i, s := range samples {
+		cpu[i], rss[i], fds[i], io[i] = s.CPU, float64(s.Rss), float64(s.Fds), s.IORate
+	}
+	last := samples[len(samples)-1]
+
+	value := func(v float64, format func(float64) string) string {
+		if v < 0 {
+			return "-"
+		}
+		return format(v)
+	}
+	lines := []struct {
+		name   string
+		values []float64
+		last   string
+	}{
+		{"cpu", cpu, value(last.CPU, func(v float64) string { return fmt.Sprintf("%.1f%%", v) })},
+		{"rss", rss, formatBytes(last.Rss)},
+		{"fds", fds, value(float64(last.Fds), func(v float64) string { return fmt.Sprintf("%d", int(v)) })},
+		{"io", io, value(last.IORate, func(v float64) string { return formatBytes(int64(v)) + "/s" })},
+	}
+
+	// name, sparkline and last value
+	const labelWidth, valueWidth = 4, 12
+	sparkWidth := width - labelWidth - valueWidth - 2
+	if sparkWidth < 10 {
+		sparkWidth = 10
+	}
+
+	text := fmt.Sprintf("[yellow]last %s[white]", last.Time.Sub(samples[0].Time).Round(time.Second))
+	for _, l := range lines {
+		text += fmt.Sprintf("\n%-*s [green]%-*s[white] %s", labelWidth, l.name, sparkWidth, sparkline(l.values, sparkWidth), l.last)
+	}
+	return text
+}
@@ -0,0 +1,119 @@
+package gui
+
+import (
+	"fmt"
+
+	"github.com/dixler/pst/gui/proc"
+	"github.com/rivo/tview"
+)
+
+// StackView shows the kernel stack of a thread.
+type StackView struct {
+	*tview.TextView
+	// from is the system view to return to when closed, 0 for the main
+	// page.
+	from int
+	// shown counts the stacks asked for, so a slow read doesn't replace
+	// a later one.
+	shown int
+}
+
+func NewStackView() *StackView {
+	p := &StackView{
+		TextView: tview.NewTextView().SetDynamicColors(true),
+	}
+
+	p.SetTitleAlign(tview.AlignLeft).SetTitle("kernel stack").SetBorder(true)
+	p.SetWrap(false)
+	return p
+}
+
+// ShowThread shows the kernel stack of tid. The stack is read in a
+// goroutine, as reading /proc of a task stuck in D state can block.
+func (p *StackView) ShowThread(g *Gui, pid, tid proc.PID) {
+	shown := p.loading(fmt.Sprintf("kernel stack [%s/%s]", pid, tid))
+	go func() {
+		title := fmt.Sprintf("kernel stack [%s/%s %s]", pid, tid, taskComm(tid))
+
+		text := ""
+		stack, err := proc.GetKernelStack(pid, tid)
+		if err != nil {
+			text = err.Error()
+		} else {
+			text = stack
+		}
+		p.show(g, shown, title, text)
+	}()
+}
+
+// ShowProcess shows the wait channel and kernel stack of pid followed by
+// those of each of its threads.
+func (p *StackView) ShowProcess(g *Gui, pid proc.PID) {
+	shown := p.loading(fmt.Sprintf("kernel stacks [%s]", pid))
+	go func() {
+		title := fmt.Sprintf("kernel stacks [%s %s]", pid, taskComm(pid))
+
+		text := renderStack(fmt.Sprintf("process %s", pid), proc.GetWchan(pid), "")
+		if stack, err := proc.GetProcessStack(pid); err != nil {
+			text += err.Error() + "\n"
+		} else {
+			text += stack + "\n"
+		}
+
+		threads, err := proc.GetThreads(pid)
+		if err != nil {
+			text += "\n" + err.Error()
+		}
+		for _, t := range threads {
+			text += "\n" + renderStack(fmt.Sprintf("thread %s %s", t.Tid, t.Comm), t.Wchan, t.State)
+			if stack, err := proc.GetKernelStack(pid, t.Tid); err != nil {
+				text += err.Error() + "\n"
+			} else {
+				text += stack + "\n"
+			}
+		}
+		p.show(g, shown, title, text)
+	}()
+}
+
+// loading clears the view for a new stack, returning its number.
+func (p *StackView) loading(title string) int {
+	p.shown++
+	p.SetTitle(title)
+	p.SetText("")
+	return p.shown
+}
+
+// show puts a stack read in the background into the view, unless another
+// one was asked for meanwhile.
+func (p *StackView) show(g *Gui, shown int, title, text string) {
+	g.App.QueueUpdateDraw(func() {
+		if p.shown != shown {
+			return
+		}
+		p.SetTitle(title)
+		p.SetText(text)
+		p.ScrollToBeginning()
+	})
+}
+
+// taskComm is the comm of tid from its stat, which unlike cmdline can be
+// read without the task's mm lock.
+func taskComm(tid proc.PID) string {
+	st, err := proc.GetStat(tid)
+	if err != nil {
+		return "-"
+	}
+	return st.Comm
+}
+
+func renderStack(name, wchan, state string) string {
+	if wchan == "" {
+		wchan = "-"
+	}
+	header := fmt.Sprintf("[yellow]%s[white] wchan: %s", name, wchan)
+	if state != "" {
+		header += " state: " + state
+	}
+	return header + "\n"
+}
@@ -0,0 +1,115 @@
+package gui
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/dixler/pst/gui/proc"
+	"github.com/rivo/tview"
+)
+
+// SyscallStatView shows the top syscalls of a process by count or time.
+type SyscallStatView struct {
+	*tview.TextView
+	lock     *sync.Mutex
+	byTime   bool
+	children bool
+}
+
+func NewSyscallStatView() *SyscallStatView {
+	p := &SyscallStatView{
+		TextView: tview.NewTextView().SetDynamicColors(true),
+		lock:     &sync.Mutex{},
+	}
+
+	p.SetTitleAlign(tview.AlignLeft).SetBorder(true)
+	p.SetWrap(false)
+	p.updateTitle()
+	return p
+}
+
+func (p *SyscallStatView) ToggleSort() {
+	p.lock.Lock()
+	p.byTime = !p.byTime
+	p.lock.Unlock()
+	p.updateTitle()
+}
+
+func (p *SyscallStatView) ToggleChildren() {
+	p.lock.Lock()
+	p.children = !p.children
+	p.lock.Unlock()
+	p.updateTitle()
+}
+
+// Children reports whether the stats cover the subtree of the process.
+func (p *SyscallStatView) Children() bool {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return p.children
+}
+
+func (p *SyscallStatView) updateTitle() {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	order := "count"
+	if p.byTime {
+		order = "time"
+	}
+	target := "process"
+	if p.children {
+		target = "subtree"
+	}
+	p.SetTitle(fmt.Sprintf("syscall summary [%s, by %s]", target, order))
+}
+
+func (p *SyscallStatView) UpdateViewWithPid(g *Gui, pid proc.PID) {
+	p.lock.Lock()
+	byTime, children := p.byTime, p.children
+	p.lock.Unlock()
+
+	pids := []proc.PID{pid}
+	if children {
+		pids = proc.GetDescendants(pid)
+	}
+	stats := g.ProcessManager.GetSyscallStats(pids...)
+
+	sort.Slice(stats, func(i, j int) bool {
+		a, b := stats[i], stats[j]
+		if byTime && a.Time != b.Time {
+			return a.Time > b.Time
+		}
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		return a.Nr < b.Nr
+	})
+
+	text := renderSyscallStats(stats)
+
+	g.App.QueueUpdateDraw(func() {
+		p.SetText(text)
+	})
+}
+
+func renderSyscallStats(stats []proc.SyscallStat) string {
+	if len(stats) == 0 {
+		return "no syscalls traced for this process yet"
+	}
+
+	buf := bytes.Buffer{}
+	w := tabwriter.NewWriter(&buf, 0, 8, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintf(w, "SYSCALL\tCOUNT\tTOTAL\tAVG\tP50\tP99\t\n")
+	for _, s := range stats {
+		fmt.Fprintf(w, "%s\t%d\t%s\t%s\t%s\t%s\t\n", s.Name(), s.Count,
+			formatDuration(s.Time), formatDuration(s.Avg()),
+			formatDuration(s.Percentile(0.5)), formatDuration(s.Percentile(0.99)))
+	}
+	w.Flush()
+
+	return highlightHeader(buf.String())
+}
@@ -0,0 +1,172 @@
+package gui
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"sync"
+
+	"github.com/dixler/pst/gui/proc"
+	"github.com/rivo/tview"
+)
+
+// syscallRenderSize is how many of the most recent syscalls are rendered.
+const syscallRenderSize = 1000
+
+// SyscallView streams the syscalls of a process, like strace.
+type SyscallView struct {
+	*tview.TextView
+	stream *proc.SyscallStream
+
+	lock     *sync.Mutex
+	pid      proc.PID
+	children bool
+	paused   bool
+	filter   string
+}
+
+func NewSyscallView() *SyscallView {
+	p := &SyscallView{
+		TextView: tview.NewTextView().SetDynamicColors(true),
+		stream:   proc.NewSyscallStream(),
+		lock:     &sync.Mutex{},
+	}
+
+	p.SetTitleAlign(tview.AlignLeft).SetTitle("syscalls").SetBorder(true)
+	p.SetWrap(false)
+	return p
+}
+
+// Toggle starts tracing pid, or stops tracing if already running.
+func (p *SyscallView) Toggle(pid proc.PID) error {
+	if p.stream.Running() {
+		p.stream.Stop()
+		p.updateTitle()
+		return nil
+	}
+
+	p.lock.Lock()
+	p.pid = pid
+	p.lock.Unlock()
+	return p.restart()
+}
+
+// ToggleChildren switches between tracing only the process (and its
+// threads) and tracing its whole subtree.
+func (p *SyscallView) ToggleChildren() error {
+	p.lock.Lock()
+	p.children = !p.children
+	p.lock.Unlock()
+
+	if !p.stream.Running() {
+		p.updateTitle()
+		return nil
+	}
+	return p.restart()
+}
+
+func (p *SyscallView) restart() error {
+	p.lock.Lock()
+	pids := []proc.PID{p.pid}
+	if p.children {
+		pids = proc.GetDescendants(p.pid)
+	}
+	p.lock.Unlock()
+
+	err := p.stream.Start(proc.Filter{Pids: pids})
+	p.updateTitle()
+	return err
+}
+
+func (p *SyscallView) TogglePause() {
+	p.lock.Lock()
+	p.paused = !p.paused
+	p.lock.Unlock()
+	p.updateTitle()
+}
+
+func (p *SyscallView) Filter() string {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return p.filter
+}
+
+func (p *SyscallView) SetFilter(filter string) {
+	p.lock.Lock()
+	p.filter = filter
+	p.lock.Unlock()
+	p.updateTitle()
+}
+
+func (p *SyscallView) updateTitle() {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	status := []string{}
+	if p.stream.Running() {
+		target := p.pid.String()
+		if p.children {
+			target += " and children"
+		}
+		status = append(status, "tracing "+target)
+	}
+	if p.paused {
+		status = append(status, "paused")
+	}
+	if p.filter != "" {
+		status = append(status, fmt.Sprintf("filter '%s'", p.filter))
+	}
+
+	title := "syscalls"
+	if len(status) > 0 {
+		title = fmt.Sprintf("syscalls [%s]", strings.Join(status, ", "))
+	}
+	p.SetTitle(title)
+}
+
+// lines returns the recorded syscalls matching the filter.
+func (p *SyscallView) lines() []string {
+	filter := p.Filter()
+	events, _ := p.stream.Events()
+
+	lines := make([]string, 0, len(events))
+	for _, e := range events {
+		if filter != "" && !strings.Contains(e.Name(), filter) {
+			continue
+		}
+		lines = append(lines, e.String())
+	}
+	return lines
+}
+
+// Save writes the recorded syscalls matching the filter to file.
+func (p *SyscallView) Save(file string) (int, error) {
+	lines := p.lines()
+	err := ioutil.WriteFile(file, []byte(strings.Join(lines, "\n")+"\n"), 0644)
+	return len(lines), err
+}
+
+func (p *SyscallView) UpdateViewWithPid(g *Gui, pid proc.PID) {
+	p.lock.Lock()
+	paused := p.paused
+	p.lock.Unlock()
+	if paused {
+		return
+	}
+
+	text := ""
+	lines := p.lines()
+	if !p.stream.Running() && len(lines) == 0 {
+		text = "press s to trace the syscalls of the selected process"
+	} else {
+		if len(lines) > syscallRenderSize {
+			lines = lines[len(lines)-syscallRenderSize:]
+		}
+		text = strings.Join(lines, "\n")
+	}
+
+	g.App.QueueUpdateDraw(func() {
+		p.SetText(text)
+		p.ScrollToEnd()
+	})
+}
@@ -0,0 +1,69 @@
+package gui
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/dixler/pst/gui/proc"
+)
+
+var threadHeaders = []string{
+	"Tid",
+	"Name",
+	"State",
+	"CPU%",
+	"Last CPU",
+	"Wchan",
+	"Vol CS",
+	"Invol CS",
+}
+
+// ThreadView lists the threads of a process.
+type ThreadView struct {
+	*PidTable
+	pid proc.PID
+}
+
+func NewThreadView() *ThreadView {
+	return &ThreadView{
+		PidTable: NewPidTable("process threads"),
+	}
+}
+
+// Pid is the process whose threads are shown.
+func (p *ThreadView) Pid() proc.PID {
+	return p.pid
+}
+
+func (p *ThreadView) UpdateViewWithPid(g *Gui, pid proc.PID) {
+	threads, err := proc.GetThreads(pid)
+
+	rows := make([][]string, 0, len(threads))
+	tids := make([]proc.PID, 0, len(threads))
+	if err != nil {
+		rows = append(rows, []string{err.Error()})
+		tids = append(tids, "")
+	}
+	for _, t := range threads {
+		cpu := "-"
+		if pct, ok := g.ProcessManager.GetThreadCPU(t.Tid); ok {
+			cpu = fmt.Sprintf("%.1f", pct)
+		}
+		rows = append(rows, []string{
+			t.Tid.String(),
+			t.Comm,
+			t.State,
+			cpu,
+			strconv.Itoa(t.Processor),
+			t.Wchan,
+			strconv.FormatUint(t.VoluntarySwitches, 10),
+			strconv.FormatUint(t.InvoluntarySwitches, 10),
+		})
+		tids = append(tids, t.Tid)
+	}
+
+	g.App.QueueUpdateDraw(func() {
+		p.pid = pid
+		p.SetRows(threadHeaders, rows, tids)
+	})
+}
@@ -2,11 +2,16 @@ package main
 
 import (
 	"flag"
+	"fmt"
 	"io/ioutil"
+	"os"
 	"runtime"
 	"runtime/debug"
+	"strings"
+	"text/tabwriter"
 
 	"github.com/dixler/pst/gui"
+	"github.com/dixler/pst/gui/proc"
 )
 
 var (
@@ -17,6 +22,11 @@ var (
 func run() int {
 	flag.Parse()
 
+	switch flag.Arg(0) {
+	case "fuser":
+		return fuser(flag.Args()[1:])
+	}
+
 	if err := gui.New().Run(); err != nil {
 		return 1
 	}
@@ -24,6 +34,40 @@ func run() int {
 	return 0
 }
 
+// fuser prints the processes holding the given files or mount points.
+func fuser(targets []string) int {
+	if len(targets) == 0 {
+		fmt.Fprintln(os.Stderr, "usage: pst fuser <path|mount point>...")
+		return 1
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "PID\tCMD\tUSE\tFD\tTARGET")
+	found := false
+	for _, t := range targets {
+		holders, err := proc.FileHolders(t)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		for _, h := range holders {
+			fd := "-"
+			if h.Use == "fd" {
+				fd = fmt.Sprint(h.Fd.Fd)
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", h.Pid,
+				strings.TrimSpace(proc.GetCommand(h.Pid)), h.Use, fd, h.Target)
+			found = true
+		}
+	}
+	w.Flush()
+
+	if !found {
+		return 1
+	}
+	return 0
+}
+
 func main() {
 	// TODO implements windows
 	if runtime.GOOS == "windows" {
@@ -35,5 +79,5 @@ func main() {
 			ioutil.WriteFile("crashdump.txt", []byte(debug.Stack()), 0666)
 		}
 	}()
-	run()
+	os.Exit(run())
 }